@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const defaultCacheTTL = 10 * time.Minute
+
+type API struct {
+	app   *App
+	ttl   time.Duration
+	now   func() time.Time
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+	// logins are the users the /users endpoints answer for, so that callers can not spend the GitHub token on any login
+	logins map[string]bool
+	// signingSecret enables the Slack endpoints, which answer for defaultLogin when no login is given
+	signingSecret string
+	defaultLogin  string
+	// background tracks work that outlives a request, such as answering a slash command later
+	background sync.WaitGroup
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+type StreakResponse struct {
+	Login                  string  `json:"login"`
+	Today                  string  `json:"today"`
+	TodayContributionCount int     `json:"todayContributionCount"`
+	Streak                 int     `json:"streak"`
+	Total                  int     `json:"total"`
+	Average                float64 `json:"average"`
+	From                   string  `json:"from"`
+}
+
+type CalendarResponse struct {
+	Login string            `json:"login"`
+	From  string            `json:"from"`
+	To    string            `json:"to"`
+	Days  []ContributionDay `json:"days"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func cacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("API_CACHE_TTL"))
+	if err != nil || ttl < 0 {
+		return defaultCacheTTL
+	}
+	return ttl
+}
+
+func newAPI(app *App, ttl time.Duration) *API {
+	return &API{
+		app:           app,
+		ttl:           ttl,
+		now:           time.Now,
+		cache:         map[string]cacheEntry{},
+		logins:        map[string]bool{},
+		signingSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		defaultLogin:  os.Getenv("GH_USER_NAME"),
+	}
+}
+
+func (api *API) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("GET /metrics", metrics)
+	mux.HandleFunc("GET /users/{login}/streak", api.streak)
+	mux.HandleFunc("GET /users/{login}/calendar", api.calendar)
+	if api.app.store != nil {
+		mux.HandleFunc("GET /leaderboard", api.leaderboard)
+	}
+	if api.signingSecret != "" {
+		mux.HandleFunc("POST /slack/commands", api.slashCommand)
+		mux.HandleFunc("POST /slack/interactions", api.interaction)
+	}
+	return mux
+}
+
+// tracked answers 404 for a login that is not configured and reports whether the request may go on.
+func (api *API) tracked(w http.ResponseWriter, login string) bool {
+	if !api.logins[login] {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: login + " is not tracked"})
+		return false
+	}
+	return true
+}
+
+// cached returns the value stored under key, calling fetch only when it is missing or expired.
+// Expired entries are swept whenever a new one is stored, so the cache only holds what is still fresh.
+func (api *API) cached(key string, fetch func() (any, error)) (any, error) {
+	api.mu.Lock()
+	entry, ok := api.cache[key]
+	api.mu.Unlock()
+	if ok && api.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	now := api.now()
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	for k, entry := range api.cache {
+		if !now.Before(entry.expires) {
+			delete(api.cache, k)
+		}
+	}
+	api.cache[key] = cacheEntry{value: value, expires: now.Add(api.ttl)}
+	return value, nil
+}
+
+func (api *API) streak(w http.ResponseWriter, req *http.Request) {
+	login := req.PathValue("login")
+	if !api.tracked(w, login) {
+		return
+	}
+	location, err := time.LoadLocation(req.URL.Query().Get("tz"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	result, err := api.cachedStreak(req.Context(), login, api.now().In(location))
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result.response())
+}
+
+func (api *API) cachedStreak(ctx context.Context, login string, now time.Time) (Result, error) {
+	value, err := api.cached("streak/"+login+"/"+now.Format("2006-01-02"), func() (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout())
+		defer cancel()
+		return api.app.streak(ctx, login, now)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return value.(Result), nil
+}
+
+func (api *API) calendar(w http.ResponseWriter, req *http.Request) {
+	login := req.PathValue("login")
+	if !api.tracked(w, login) {
+		return
+	}
+	from, to, err := parseRange(req.URL.Query().Get("from"), req.URL.Query().Get("to"), api.now())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	// one query per request at most, longer ranges are for the CLI
+	if len(rangeWindows(from, to)) > 1 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "range must be within a year"})
+		return
+	}
+	value, err := api.cached("calendar/"+login+"/"+from.Format("2006-01-02")+"/"+to.Format("2006-01-02"), func() (any, error) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout())
+		defer cancel()
+		days, err := api.app.fetchCalendar(ctx, login, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return CalendarResponse{Login: login, From: from.Format("2006-01-02"), To: to.Format("2006-01-02"), Days: days}, nil
+	})
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, value)
+}
+
+func (client Client) fetchWeeks(ctx context.Context, login string, from time.Time, to time.Time) ([]Week, error) {
+	var query Query
+	variables := map[string]interface{}{
+		"name": githubv4.String(login),
+		"from": githubv4.DateTime{Time: from},
+		"to":   githubv4.DateTime{Time: to},
+	}
+	if err := client.Query(ctx, &query, variables); err != nil {
+		return nil, err
+	}
+	return query.User.ContributionsCollection.ContributionCalendar.Weeks, nil
+}
+
+func (r *Result) response() StreakResponse {
+	return StreakResponse{
+		Login:                  r.userName,
+		Today:                  r.today.Format("2006-01-02"),
+		TodayContributionCount: r.todayContributionCount,
+		Streak:                 r.streak,
+		Total:                  r.total,
+		Average:                r.average(),
+		From:                   r.latestDay.Format("2006-01-02"),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("can not write response", "err", err)
+	}
+}
+
+func (api *API) leaderboard(w http.ResponseWriter, req *http.Request) {
+	results, err := api.app.store.leaderboard(req.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	responses := make([]StreakResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, result.response())
+	}
+	writeJSON(w, http.StatusOK, responses)
+}
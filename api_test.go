@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func newTestAPI(t *testing.T, handler http.HandlerFunc) *API {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", handler)
+	state, _ := loadState("")
+	app := &App{graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}), strategy: "sequential", state: state}
+	api := newAPI(app, time.Minute)
+	api.logins["octocat"] = true
+	api.now = func() time.Time { return time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC) }
+	return api
+}
+
+func TestAPIHealthz(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {})
+	rec := httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"status":"ok"}` {
+		t.Errorf("GET /healthz = %v %v", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAPIStreak(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+
+	tests := []struct {
+		name       string
+		path       string
+		status     int
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "ok",
+			path:       "/users/octocat/streak",
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+			want:       `{"login":"octocat","today":"2023-01-03","todayContributionCount":1,"streak":1,"total":1,"average":1,"from":"2023-01-03"}`,
+		},
+		{
+			name:       "githubIsError",
+			path:       "/users/octocat/streak",
+			status:     http.StatusInternalServerError,
+			wantStatus: http.StatusBadGateway,
+			want:       `{"error":"non-200 OK status code: 500 Internal Server Error body: \"\""}`,
+		},
+		{
+			name:       "unknownTimezone",
+			path:       "/users/octocat/streak?tz=Mars/Olympus",
+			status:     http.StatusOK,
+			wantStatus: http.StatusBadRequest,
+			want:       `{"error":"unknown time zone Mars/Olympus"}`,
+		},
+		{
+			name:       "untrackedLogin",
+			path:       "/users/hubot/streak",
+			status:     http.StatusOK,
+			wantStatus: http.StatusNotFound,
+			want:       `{"error":"hubot is not tracked"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+				requests++
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write(todayIsOneJson)
+			})
+			handler := api.handler()
+			for range 2 {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+				if rec.Code != tt.wantStatus {
+					t.Errorf("GET %s status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+				}
+				if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+					t.Errorf("GET %s = %v, want %v", tt.path, got, tt.want)
+				}
+			}
+			if tt.wantStatus == http.StatusOK && requests != 1 {
+				t.Errorf("GET %s requests = %v, want cached after 1", tt.path, requests)
+			}
+		})
+	}
+}
+
+func TestAPICacheExpires(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	requests := 0
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Write(todayIsOneJson)
+	})
+	now := time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC)
+	api.now = func() time.Time { return now }
+	handler := api.handler()
+	for _, d := range []time.Duration{0, 30 * time.Second, 31 * time.Second} {
+		now = now.Add(d)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/octocat/streak", nil))
+	}
+	if requests != 2 {
+		t.Errorf("requests = %v, want %v", requests, 2)
+	}
+}
+
+func TestAPICacheSweepsExpired(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {})
+	now := time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC)
+	api.now = func() time.Time { return now }
+	for _, key := range []string{"a", "b", "c"} {
+		api.cached(key, func() (any, error) { return key, nil })
+		now = now.Add(40 * time.Second)
+	}
+	if _, ok := api.cache["a"]; ok || len(api.cache) != 2 {
+		t.Errorf("cache = %v, want a swept", api.cache)
+	}
+}
+
+func TestAPICalendar(t *testing.T) {
+	totalContributionsIsOneJson, _ := testData.ReadFile("testdata/ExecQuery/totalContributionsIsOne.json")
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantFrom   string
+		wantTo     string
+		want       string
+	}{
+		{
+			name:       "default",
+			path:       "/users/octocat/calendar",
+			wantStatus: http.StatusOK,
+			wantFrom:   "2022-01-03",
+			wantTo:     "2023-01-03",
+			want:       `{"login":"octocat","from":"2022-01-03","to":"2023-01-03","days":[{"contributionCount":1,"date":"2023-01-01","weekday":0}]}`,
+		},
+		{
+			name:       "range",
+			path:       "/users/octocat/calendar?from=2022-12-01&to=2022-12-31",
+			wantStatus: http.StatusOK,
+			wantFrom:   "2022-12-01",
+			wantTo:     "2022-12-31",
+			// days GitHub returns outside of the range are dropped
+			want: `{"login":"octocat","from":"2022-12-01","to":"2022-12-31","days":[]}`,
+		},
+		{
+			name:       "invalidFrom",
+			path:       "/users/octocat/calendar?from=2022/12/01",
+			wantStatus: http.StatusBadRequest,
+			want:       `{"error":"from must be YYYY-MM-DD"}`,
+		},
+		{
+			name:       "reversed",
+			path:       "/users/octocat/calendar?from=2022-12-31&to=2022-12-01",
+			wantStatus: http.StatusBadRequest,
+			want:       `{"error":"from must not be after to"}`,
+		},
+		{
+			name:       "overAYear",
+			path:       "/users/octocat/calendar?from=2021-01-01&to=2022-12-31",
+			wantStatus: http.StatusBadRequest,
+			want:       `{"error":"range must be within a year"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFrom, gotTo string
+			api := newTestAPI(t, func(w http.ResponseWriter, req *http.Request) {
+				var body struct {
+					Variables struct {
+						From time.Time `json:"from"`
+						To   time.Time `json:"to"`
+					} `json:"variables"`
+				}
+				json.NewDecoder(req.Body).Decode(&body)
+				gotFrom, gotTo = body.Variables.From.Format("2006-01-02"), body.Variables.To.Format("2006-01-02")
+				w.Write(totalContributionsIsOneJson)
+			})
+			rec := httptest.NewRecorder()
+			api.handler().ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %v, want %v", tt.path, rec.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+				t.Errorf("GET %s = %v, want %v", tt.path, got, tt.want)
+			}
+			if tt.wantStatus == http.StatusOK && (gotFrom != tt.wantFrom || gotTo != tt.wantTo) {
+				t.Errorf("GET %s queried %v ~ %v, want %v ~ %v", tt.path, gotFrom, gotTo, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
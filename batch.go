@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	defaultBatchWindows = 4
+	// each window costs a full year of calendar nodes, so keep a single query well below GitHub's limits
+	maxBatchWindows = 10
+)
+
+func batchWindows() int {
+	windows, err := strconv.Atoi(os.Getenv("GH_BATCH_WINDOWS"))
+	if err != nil || windows < 1 {
+		return defaultBatchWindows
+	}
+	return min(windows, maxBatchWindows)
+}
+
+// batchQueryType builds a query like Query whose user has one aliased
+// contributionsCollection per window: w0 ends at $to0, w1 at $to1, and so on.
+func batchQueryType(windows int) reflect.Type {
+	fields := make([]reflect.StructField, windows)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("W%d", i),
+			Type: reflect.TypeOf(ContributionsCollection{}),
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"w%d: contributionsCollection(from: $from%d to: $to%d)"`, i, i, i)),
+		}
+	}
+	return reflect.StructOf([]reflect.StructField{{
+		Name: "User",
+		Type: reflect.StructOf(fields),
+		Tag:  `graphql:"user(login: $name)"`,
+	}})
+}
+
+func (r *Result) batchVariables(start time.Time, windows int) map[string]interface{} {
+	variables := map[string]interface{}{"name": githubv4.String(r.userName)}
+	for i := range windows {
+		to := start.AddDate(0, 0, -365*i)
+		variables[fmt.Sprintf("from%d", i)] = githubv4.DateTime{Time: to.AddDate(0, 0, -365)}
+		variables[fmt.Sprintf("to%d", i)] = githubv4.DateTime{Time: to}
+	}
+	return variables
+}
+
+func (client Client) execBatchQuery(ctx context.Context, variables map[string]interface{}, windows int) ([]Query, error) {
+	batch := reflect.New(batchQueryType(windows))
+	start := time.Now()
+	err := client.Query(ctx, batch.Interface(), variables)
+	metrics.observeQuery(time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("fetched windows", "user", variables["name"], "from", variables[fmt.Sprintf("from%d", windows-1)], "to", variables["to0"], "windows", windows, "duration", time.Since(start))
+	user := batch.Elem().Field(0)
+	queries := make([]Query, windows)
+	for i := range queries {
+		queries[i] = Query{User{user.Field(i).Interface().(ContributionsCollection)}}
+	}
+	return queries, nil
+}
+
+// costErrorMessages are parts of the messages GitHub rejects a query with when it is too expensive to run.
+var costErrorMessages = []string{"exceeds the maximum", "max complexity", "resource limits"}
+
+func isCostError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, part := range costErrorMessages {
+		if strings.Contains(message, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// countOverAYearBatched gives the same result as countOverAYear but resolves
+// up to windows years of a streak with a single request. When GitHub rejects
+// the batched query as too expensive, the windows are fetched one request at
+// a time instead; any other error fails the count.
+func (r *Result) countOverAYearBatched(ctx context.Context, graphqlClient *githubv4.Client, windows int) error {
+	client := Client{graphqlClient}
+	for r.isContinue {
+		start := r.latestDay
+		queries, err := client.execBatchQuery(ctx, r.batchVariables(start, windows), windows)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if isCostError(err) {
+			slog.Warn("batch query is too expensive, fetching one window per query", "user", r.userName, "err", err)
+			return r.countOverAYear(ctx, graphqlClient)
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.countWindows(start, queries, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func TestCountOverAYearBatched(t *testing.T) {
+	todayIsZeroYesterdayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsZeroYesterdayIsOne.json")
+	allOneJson, _ := testData.ReadFile("testdata/CountOverAYear/allOne.json")
+	minusOneYearJson, _ := testData.ReadFile("testdata/CountOverAYear/minusOneYear.json")
+	minusOneYearStartsWithZeroJson, _ := testData.ReadFile("testdata/CountOverAYear/minusOneYearStartsWithZero.json")
+
+	tests := []struct {
+		name         string
+		windows      map[string][]byte
+		batchStatus  int
+		batchError   string
+		wantRequests int
+		wantErr      bool
+	}{
+		{
+			name:         "todayIsZeroYesterdayIsOne",
+			windows:      map[string][]byte{"2023-01-04": todayIsZeroYesterdayIsOneJson},
+			wantRequests: 1,
+		},
+		{
+			name:         "overAYearNotConsecutive",
+			windows:      map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearStartsWithZeroJson},
+			wantRequests: 1,
+		},
+		{
+			name:         "overAYearConsecutive",
+			windows:      map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearJson},
+			wantRequests: 1,
+		},
+		{
+			// one rejected batch, then a request per window
+			name:         "batchIsTooExpensive",
+			windows:      map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearJson},
+			batchError:   "By the time this query traverses to the contributionDays connection, it is requesting up to 1,000,001 possible nodes which exceeds the maximum limit of 500,000.",
+			wantRequests: 3,
+		},
+		{
+			name:         "batchIsError",
+			windows:      map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearJson},
+			batchStatus:  http.StatusInternalServerError,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		requests := 0
+		batching := false
+		mux := http.NewServeMux()
+		client := githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			var body struct {
+				Query     string               `json:"query"`
+				Variables map[string]time.Time `json:"variables"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			if batching {
+				requests++
+			}
+			if !strings.Contains(body.Query, "w0:") {
+				w.Write(tt.windows[body.Variables["to"].Format("2006-01-02")])
+				return
+			}
+			if tt.batchStatus != 0 {
+				w.WriteHeader(tt.batchStatus)
+				return
+			}
+			if tt.batchError != "" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": nil, "errors": []map[string]string{{"message": tt.batchError}}})
+				return
+			}
+			user := map[string]json.RawMessage{}
+			for i := 0; ; i++ {
+				to, ok := body.Variables[fmt.Sprintf("to%d", i)]
+				if !ok {
+					break
+				}
+				var window struct {
+					Data struct {
+						User struct {
+							ContributionsCollection json.RawMessage `json:"contributionsCollection"`
+						} `json:"user"`
+					} `json:"data"`
+				}
+				json.Unmarshal(tt.windows[to.Format("2006-01-02")], &window)
+				user[fmt.Sprintf("w%d", i)] = window.Data.User.ContributionsCollection
+				if window.Data.User.ContributionsCollection == nil {
+					user[fmt.Sprintf("w%d", i)] = json.RawMessage("null")
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"user": user}})
+		})
+		t.Run(tt.name, func(t *testing.T) {
+			want := &Result{userName: "octocat", today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), isContinue: true}
+			if err := want.countOverAYear(context.Background(), client); err != nil {
+				t.Fatalf("countOverAYear() err = %v", err)
+			}
+			requests = 0
+			batching = true
+			r := &Result{userName: "octocat", today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), isContinue: true}
+			err := r.countOverAYearBatched(context.Background(), client, 4)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("countOverAYearBatched() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("countOverAYearBatched() requests = %v, want %v", requests, tt.wantRequests)
+			}
+			if tt.wantErr {
+				return
+			}
+			if *r != *want {
+				t.Errorf("countOverAYearBatched() = %+v, want %+v", *r, *want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"time"
+)
+
+// contributionLevels are GitHub's ContributionLevel values in order of level.
+var contributionLevels = [5]string{"NONE", "FIRST_QUARTILE", "SECOND_QUARTILE", "THIRD_QUARTILE", "FOURTH_QUARTILE"}
+
+// levelScale buckets counts like GitHub's calendar: level 0 is no
+// contributions and levels 1 to 4 are quarters of the busiest day.
+type levelScale struct {
+	max int
+}
+
+func newLevelScale(weeks []Week) levelScale {
+	var scale levelScale
+	for _, week := range weeks {
+		for _, day := range week.ContributionDays {
+			scale.max = max(scale.max, day.ContributionCount)
+		}
+	}
+	return scale
+}
+
+func (s levelScale) level(count int) int {
+	if count <= 0 {
+		return 0
+	}
+	if count >= s.max {
+		return 4
+	}
+	return (count*4 + s.max - 1) / s.max
+}
+
+// dayLevel prefers the level GitHub gave day, so colors match its calendar,
+// and buckets the count itself for days without one.
+func (s levelScale) dayLevel(day ContributionDay) int {
+	if level := slices.Index(contributionLevels[:], day.ContributionLevel); level >= 0 {
+		return level
+	}
+	return s.level(day.ContributionCount)
+}
+
+// dayColor prefers the color GitHub gave day, so the calendar looks exactly
+// like its own, and uses the light theme color of its level for days without one.
+func (s levelScale) dayColor(day ContributionDay) color.RGBA {
+	var c color.RGBA
+	if n, err := fmt.Sscanf(day.Color, "#%02x%02x%02x", &c.R, &c.G, &c.B); err == nil && n == 3 && len(day.Color) == 7 {
+		c.A = 0xff
+		return c
+	}
+	return heatmapColors[s.dayLevel(day)]
+}
+
+// calendarColumns lays out the last n weeks as columns of days indexed by
+// weekday, Sunday first; days outside the calendar are nil.
+func calendarColumns(weeks []Week, n int) [][7]*ContributionDay {
+	weeks = weeks[max(len(weeks)-n, 0):]
+	columns := make([][7]*ContributionDay, len(weeks))
+	for i, week := range weeks {
+		for j := range week.ContributionDays {
+			day := &week.ContributionDays[j]
+			d, err := time.Parse("2006-01-02", day.Date)
+			if err != nil {
+				continue
+			}
+			columns[i][d.Weekday()] = day
+		}
+	}
+	return columns
+}
+
+// weeksOf groups days, which are in order, into weeks starting on Sunday as GitHub does.
+func weeksOf(days []ContributionDay) []Week {
+	var weeks []Week
+	var sunday time.Time
+	for _, day := range days {
+		d, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		if start := d.AddDate(0, 0, -int(d.Weekday())); len(weeks) == 0 || !start.Equal(sunday) {
+			weeks = append(weeks, Week{})
+			sunday = start
+		}
+		weeks[len(weeks)-1].ContributionDays = append(weeks[len(weeks)-1].ContributionDays, day)
+	}
+	return weeks
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestLevelScale(t *testing.T) {
+	weeks := []Week{{ContributionDays: []ContributionDay{
+		{ContributionCount: 0}, {ContributionCount: 1}, {ContributionCount: 2}, {ContributionCount: 3},
+		{ContributionCount: 4}, {ContributionCount: 5}, {ContributionCount: 6}, {ContributionCount: 7}, {ContributionCount: 8},
+	}}}
+
+	tests := []struct {
+		name  string
+		weeks []Week
+		count int
+		want  int
+	}{
+		{name: "zero", weeks: weeks, count: 0, want: 0},
+		{name: "firstQuarter", weeks: weeks, count: 2, want: 1},
+		{name: "secondQuarter", weeks: weeks, count: 3, want: 2},
+		{name: "thirdQuarter", weeks: weeks, count: 6, want: 3},
+		{name: "fourthQuarter", weeks: weeks, count: 7, want: 4},
+		{name: "busiest", weeks: weeks, count: 8, want: 4},
+		{name: "aboveCalendar", weeks: weeks, count: 100, want: 4},
+		{name: "emptyCalendar", weeks: nil, count: 1, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLevelScale(tt.weeks).level(tt.count); got != tt.want {
+				t.Errorf("level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarColumns(t *testing.T) {
+	// GitHub starts every week on Sunday, so only the first and the last week are partial
+	weeks := []Week{
+		{ContributionDays: []ContributionDay{{Date: "2022-12-27"}, {Date: "2022-12-28"}, {Date: "2022-12-29"}, {Date: "2022-12-30"}, {Date: "2022-12-31"}}},
+		{ContributionDays: []ContributionDay{{Date: "2023-01-01"}, {Date: "2023-01-02"}, {Date: "2023-01-03"}}},
+	}
+
+	tests := []struct {
+		name  string
+		n     int
+		weeks []Week
+		want  [][7]string
+	}{
+		{
+			name:  "all",
+			n:     53,
+			weeks: weeks,
+			want:  [][7]string{{"", "", "2022-12-27", "2022-12-28", "2022-12-29", "2022-12-30", "2022-12-31"}, {"2023-01-01", "2023-01-02", "2023-01-03", "", "", "", ""}},
+		},
+		{
+			name:  "last",
+			n:     1,
+			weeks: weeks,
+			want:  [][7]string{{"2023-01-01", "2023-01-02", "2023-01-03", "", "", "", ""}},
+		},
+		{
+			name:  "empty",
+			n:     53,
+			weeks: nil,
+			want:  [][7]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns := calendarColumns(tt.weeks, tt.n)
+			got := make([][7]string, len(columns))
+			for i, column := range columns {
+				for j, day := range column {
+					if day != nil {
+						got[i][j] = day.Date
+					}
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("calendarColumns() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("calendarColumns()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDayLevel(t *testing.T) {
+	scale := levelScale{max: 8}
+
+	tests := []struct {
+		name string
+		day  ContributionDay
+		want int
+	}{
+		{name: "githubLevel", day: ContributionDay{ContributionCount: 2, ContributionLevel: "THIRD_QUARTILE"}, want: 3},
+		{name: "githubNone", day: ContributionDay{ContributionCount: 0, ContributionLevel: "NONE"}, want: 0},
+		{name: "withoutLevel", day: ContributionDay{ContributionCount: 2}, want: 1},
+		{name: "unknownLevel", day: ContributionDay{ContributionCount: 8, ContributionLevel: "FIFTH_QUARTILE"}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scale.dayLevel(tt.day); got != tt.want {
+				t.Errorf("dayLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDayColor(t *testing.T) {
+	scale := levelScale{max: 8}
+
+	tests := []struct {
+		name string
+		day  ContributionDay
+		want color.RGBA
+	}{
+		{name: "githubColor", day: ContributionDay{ContributionCount: 2, ContributionLevel: "THIRD_QUARTILE", Color: "#26a641"}, want: color.RGBA{0x26, 0xa6, 0x41, 0xff}},
+		{name: "withoutColor", day: ContributionDay{ContributionCount: 2, ContributionLevel: "THIRD_QUARTILE"}, want: heatmapColors[3]},
+		{name: "invalidColor", day: ContributionDay{ContributionCount: 8, Color: "green"}, want: heatmapColors[4]},
+		{name: "shortColor", day: ContributionDay{ContributionCount: 0, Color: "#fff"}, want: heatmapColors[0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scale.dayColor(tt.day); got != tt.want {
+				t.Errorf("dayColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
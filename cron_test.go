@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleError(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{name: "fewFields", spec: "37 11 * *", want: `schedule "37 11 * *" must have 5 fields`},
+		{name: "outOfRange", spec: "60 11 * * *", want: `schedule "60 11 * * *": "60" is out of range 0-59`},
+		{name: "reversedRange", spec: "0 12-11 * * *", want: `schedule "0 12-11 * * *": "12-11" is out of range 0-23`},
+		{name: "invalidStep", spec: "*/0 * * * *", want: `schedule "*/0 * * * *": invalid step "*/0"`},
+		{name: "invalidValue", spec: "0 0 * JAN *", want: `schedule "0 0 * JAN *": invalid value "JAN"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchedule(tt.spec)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("parseSchedule() err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "laterToday",
+			spec: "37 11 * * *",
+			from: time.Date(2023, 1, 3, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC),
+		},
+		{
+			name: "sameMinuteIsNext",
+			spec: "37 11 * * *",
+			from: time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 4, 11, 37, 0, 0, time.UTC),
+		},
+		{
+			name: "list",
+			spec: "0 18,21,23 * * *",
+			from: time.Date(2023, 1, 3, 19, 30, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 3, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "step",
+			spec: "*/15 * * * *",
+			from: time.Date(2023, 1, 3, 23, 50, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekdaysOnly",
+			spec: "0 9 * * 1-5",
+			from: time.Date(2023, 1, 6, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 9, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sundayIsSeven",
+			spec: "0 9 * * 7",
+			from: time.Date(2023, 1, 3, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 8, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "dayOfMonthOrDayOfWeek",
+			spec: "0 0 15 * 1",
+			from: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "nextYear",
+			spec: "0 0 1 1 *",
+			from: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "inLocation",
+			spec: "0 21 * * *",
+			from: time.Date(2023, 1, 3, 13, 0, 0, 0, time.UTC).In(tokyo),
+			want: time.Date(2023, 1, 4, 21, 0, 0, 0, tokyo),
+		},
+		{
+			name: "skippedByDaylightSaving",
+			spec: "30 2 * * *",
+			from: time.Date(2023, 3, 12, 0, 0, 0, 0, newYork),
+			want: time.Date(2023, 3, 13, 2, 30, 0, 0, newYork),
+		},
+		{
+			name: "never",
+			spec: "0 0 30 2 *",
+			from: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+			want: time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("parseSchedule() err = %v", err)
+			}
+			if got := s.next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
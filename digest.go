@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	digestWeekly  = "weekly"
+	digestMonthly = "monthly"
+	// digestRepositories is how many of the most committed repositories a digest lists
+	digestRepositories = 5
+)
+
+// Period is a range of whole days, both ends included.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+func (p Period) days() int {
+	return int(p.To.Sub(p.From).Hours()/24) + 1
+}
+
+type RepositoryContribution struct {
+	NameWithOwner string
+	Commits       int
+}
+
+type RepositoryQuery struct {
+	User struct {
+		ContributionsCollection struct {
+			CommitContributionsByRepository []struct {
+				Repository struct {
+					NameWithOwner string
+				}
+				Contributions struct {
+					TotalCount int
+				}
+			} `graphql:"commitContributionsByRepository(maxRepositories: $maxRepositories)"`
+		} `graphql:"contributionsCollection(from: $from to: $to)"`
+	} `graphql:"user(login: $name)"`
+}
+
+type Digest struct {
+	login              string
+	period             string
+	current            Period
+	previous           Period
+	activeDays         int
+	total              int
+	previousActiveDays int
+	previousTotal      int
+	streak             int
+	previousStreak     int
+	repositories       []RepositoryContribution
+	// risks are the days of the coming week that are often skipped
+	risks []Risk
+}
+
+// digestPeriods returns the period a digest posted today covers and the one before it:
+// the last seven days for weekly, the last calendar month for monthly.
+func digestPeriods(period string, today time.Time) (Period, Period, error) {
+	switch period {
+	case digestWeekly:
+		return Period{today.AddDate(0, 0, -7), today.AddDate(0, 0, -1)}, Period{today.AddDate(0, 0, -14), today.AddDate(0, 0, -8)}, nil
+	case digestMonthly:
+		month := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		return Period{month.AddDate(0, -1, 0), month.AddDate(0, 0, -1)}, Period{month.AddDate(0, -2, 0), month.AddDate(0, -1, -1)}, nil
+	}
+	return Period{}, Period{}, fmt.Errorf("unknown digest period %q", period)
+}
+
+// newDigest summarizes days, which cover the year before current ends, so streaks are counted within that year.
+func newDigest(login string, period string, current Period, previous Period, days []ContributionDay, repositories []RepositoryContribution) Digest {
+	digest := Digest{login: login, period: period, current: current, previous: previous, repositories: repositories}
+	counts := map[string]int{}
+	for _, day := range days {
+		counts[day.Date] = day.ContributionCount
+	}
+	for d := current.From; !d.After(current.To); d = d.AddDate(0, 0, 1) {
+		if count := counts[d.Format("2006-01-02")]; count > 0 {
+			digest.activeDays++
+			digest.total += count
+		}
+	}
+	for d := previous.From; !d.After(previous.To); d = d.AddDate(0, 0, 1) {
+		if count := counts[d.Format("2006-01-02")]; count > 0 {
+			digest.previousActiveDays++
+			digest.previousTotal += count
+		}
+	}
+	streak := func(end time.Time) int {
+		n := 0
+		for d := end; counts[d.Format("2006-01-02")] > 0; d = d.AddDate(0, 0, -1) {
+			n++
+		}
+		return n
+	}
+	digest.streak, digest.previousStreak = streak(current.To), streak(previous.To)
+	digest.risks = newForecast(days).atRisk(current.To.AddDate(0, 0, 1), 7)
+	return digest
+}
+
+func (d *Digest) createMessage() string {
+	title, previous := "週間まとめ", "前週"
+	if d.period == digestMonthly {
+		title, previous = "月間まとめ", "前月"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sの%s(%s ~ %s)\n", d.login, title, d.current.From.Format("2006-01-02"), d.current.To.Format("2006-01-02"))
+	fmt.Fprintf(&b, "コミットした日は%d/%d日(%sは%d/%d日)\n", d.activeDays, d.current.days(), previous, d.previousActiveDays, d.previous.days())
+	fmt.Fprintf(&b, "合計コミット数は%d(%s比%+d)\n", d.total, previous, d.total-d.previousTotal)
+	fmt.Fprintf(&b, "期間末の連続コミット日数は%d(%s末は%d)\n", d.streak, previous, d.previousStreak)
+	if len(d.repositories) > 0 {
+		b.WriteString("よくコミットしたリポジトリ\n")
+		for _, repository := range d.repositories {
+			fmt.Fprintf(&b, "• %s %d\n", repository.NameWithOwner, repository.Commits)
+		}
+	}
+	if len(d.risks) > 0 {
+		b.WriteString("コミットを忘れがちな日\n")
+		b.WriteString(createRiskMessage(d.risks) + "\n")
+	}
+	fmt.Fprintf(&b, "https://github.com/%s", d.login)
+	return b.String()
+}
+
+func (client Client) fetchRepositories(ctx context.Context, login string, period Period) ([]RepositoryContribution, error) {
+	var query RepositoryQuery
+	variables := map[string]interface{}{
+		"name": githubv4.String(login),
+		"from": githubv4.DateTime{Time: period.From},
+		// commits are counted by time, so the last day of the period ends at the next midnight
+		"to":              githubv4.DateTime{Time: period.To.AddDate(0, 0, 1)},
+		"maxRepositories": githubv4.Int(digestRepositories),
+	}
+	if err := client.Query(ctx, &query, variables); err != nil {
+		return nil, err
+	}
+	repositories := []RepositoryContribution{}
+	for _, c := range query.User.ContributionsCollection.CommitContributionsByRepository {
+		repositories = append(repositories, RepositoryContribution{NameWithOwner: c.Repository.NameWithOwner, Commits: c.Contributions.TotalCount})
+	}
+	return repositories, nil
+}
+
+func (a *App) digest(ctx context.Context, login string, now time.Time, period string) (Digest, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	current, previous, err := digestPeriods(period, today)
+	if err != nil {
+		return Digest{}, err
+	}
+	days, err := a.fetchCalendar(ctx, login, current.To.AddDate(0, 0, -365), current.To)
+	if err != nil {
+		return Digest{}, err
+	}
+	repositories, err := Client{a.graphqlClient}.fetchRepositories(ctx, login, current)
+	if err != nil {
+		return Digest{}, err
+	}
+	return newDigest(login, period, current, previous, days, repositories), nil
+}
+
+// postDigest posts the digest of period to where the reports of login go.
+func (a *App) postDigest(ctx context.Context, login string, now time.Time, period string) {
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	digest, err := a.digest(ctx, login, now, period)
+	if err != nil {
+		slog.Error("can not make digest", "user", login, "period", period, "err", err)
+		errCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slackErrorTimeout)
+		defer cancel()
+		a.slackClient.postSlackError(errCtx)
+		return
+	}
+	a.slackClient.postSlackTo(ctx, a.channel(ctx, login), digest.createMessage())
+}
+
+// digestCommand posts a digest once, for running it from cron or GitHub Actions on the day it is due.
+func (a *App) digestCommand(ctx context.Context, args []string) error {
+	fs := newFlagSet("digest")
+	login := fs.String("login", os.Getenv("GH_USER_NAME"), "GitHub login to summarize")
+	period := fs.String("period", digestWeekly, "period to summarize: weekly or monthly")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("-login is required")
+	}
+	if _, _, err := digestPeriods(*period, time.Time{}); err != nil {
+		return err
+	}
+	a.postDigest(ctx, *login, a.now(), *period)
+	return nil
+}
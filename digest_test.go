@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func date(value string) time.Time {
+	d, _ := time.Parse("2006-01-02", value)
+	return d
+}
+
+func TestDigestPeriods(t *testing.T) {
+	tests := []struct {
+		name         string
+		period       string
+		today        time.Time
+		wantCurrent  Period
+		wantPrevious Period
+		wantErr      bool
+	}{
+		{name: "weekly", period: digestWeekly, today: date("2023-01-09"), wantCurrent: Period{date("2023-01-02"), date("2023-01-08")}, wantPrevious: Period{date("2022-12-26"), date("2023-01-01")}},
+		{name: "monthly", period: digestMonthly, today: date("2023-03-01"), wantCurrent: Period{date("2023-02-01"), date("2023-02-28")}, wantPrevious: Period{date("2023-01-01"), date("2023-01-31")}},
+		{name: "monthlyMidMonth", period: digestMonthly, today: date("2023-01-15"), wantCurrent: Period{date("2022-12-01"), date("2022-12-31")}, wantPrevious: Period{date("2022-11-01"), date("2022-11-30")}},
+		{name: "unknown", period: "daily", today: date("2023-01-09"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, previous, err := digestPeriods(tt.period, tt.today)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("digestPeriods() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if current != tt.wantCurrent || previous != tt.wantPrevious {
+				t.Errorf("digestPeriods() = %v %v, want %v %v", current, previous, tt.wantCurrent, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+func TestDigestCreateMessage(t *testing.T) {
+	days := []ContributionDay{
+		{Date: "2022-12-30", ContributionCount: 1},
+		{Date: "2022-12-31", ContributionCount: 2},
+		{Date: "2023-01-01", ContributionCount: 3},
+		{Date: "2023-01-02", ContributionCount: 0},
+		{Date: "2023-01-05", ContributionCount: 4},
+		{Date: "2023-01-06", ContributionCount: 1},
+		{Date: "2023-01-07", ContributionCount: 2},
+		{Date: "2023-01-08", ContributionCount: 5},
+	}
+	repositories := []RepositoryContribution{{NameWithOwner: "octocat/hello-world", Commits: 10}, {NameWithOwner: "octocat/spoon-knife", Commits: 2}}
+
+	tests := []struct {
+		name         string
+		period       string
+		repositories []RepositoryContribution
+		want         string
+	}{
+		{
+			name:         "weekly",
+			period:       digestWeekly,
+			repositories: repositories,
+			want:         "octocatの週間まとめ(2023-01-02 ~ 2023-01-08)\nコミットした日は4/7日(前週は3/7日)\n合計コミット数は12(前週比+6)\n期間末の連続コミット日数は4(前週末は3)\nよくコミットしたリポジトリ\n• octocat/hello-world 10\n• octocat/spoon-knife 2\nhttps://github.com/octocat",
+		},
+		{
+			name:         "noRepositories",
+			period:       digestWeekly,
+			repositories: []RepositoryContribution{},
+			want:         "octocatの週間まとめ(2023-01-02 ~ 2023-01-08)\nコミットした日は4/7日(前週は3/7日)\n合計コミット数は12(前週比+6)\n期間末の連続コミット日数は4(前週末は3)\nhttps://github.com/octocat",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, previous, _ := digestPeriods(tt.period, date("2023-01-09"))
+			digest := newDigest("octocat", tt.period, current, previous, days, tt.repositories)
+			if got := digest.createMessage(); got != tt.want {
+				t.Errorf("createMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigestRisks(t *testing.T) {
+	current, previous, _ := digestPeriods(digestWeekly, date("2023-01-29"))
+	digest := newDigest("octocat", digestWeekly, current, previous, forecastDays(), nil)
+	want := "コミットを忘れがちな日\n• 2023-01-29(日曜日)は過去50%の確率でコミットしていません\nhttps://github.com/octocat"
+	if got := digest.createMessage(); !strings.HasSuffix(got, want) {
+		t.Errorf("createMessage() = %v, want suffix %v", got, want)
+	}
+}
+
+func TestFetchRepositoriesRange(t *testing.T) {
+	var got struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Variables *struct {
+				From time.Time `json:"from"`
+				To   time.Time `json:"to"`
+			} `json:"variables"`
+		}
+		body.Variables = &got
+		json.NewDecoder(req.Body).Decode(&body)
+		w.Write([]byte(`{"data": {"user": {"contributionsCollection": {"commitContributionsByRepository": []}}}}`))
+	})
+	client := Client{githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})}
+	if _, err := client.fetchRepositories(context.Background(), "octocat", Period{From: date("2023-01-01"), To: date("2023-01-07")}); err != nil {
+		t.Fatalf("fetchRepositories() err = %v", err)
+	}
+	if !got.From.Equal(date("2023-01-01")) || !got.To.Equal(date("2023-01-08")) {
+		t.Errorf("fetchRepositories() queried %v ~ %v, want 2023-01-01 ~ 2023-01-08", got.From, got.To)
+	}
+}
+
+func TestPostDigest(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	okJson, _ := testData.ReadFile("testdata/slack/ok.json")
+
+	tests := []struct {
+		name         string
+		repositories int
+		want         string
+	}{
+		{name: "ok", repositories: http.StatusOK, want: "• octocat/hello-world 3\n"},
+		{name: "error", repositories: http.StatusInternalServerError, want: "count-commits-js error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+				c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+					req.ParseForm()
+					got = append(got, req.PostForm.Get("text"))
+					w.Write(okJson)
+				})
+			})
+			ts.Start()
+			defer ts.Stop()
+			t.Setenv("SLACK_CHANNEL_ID", "C0123456789")
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				if !strings.Contains(string(body), "commitContributionsByRepository") {
+					w.Write(todayIsOneJson)
+					return
+				}
+				if tt.repositories != http.StatusOK {
+					w.WriteHeader(tt.repositories)
+					return
+				}
+				w.Write([]byte(`{"data": {"user": {"contributionsCollection": {"commitContributionsByRepository": [{"repository": {"nameWithOwner": "octocat/hello-world"}, "contributions": {"totalCount": 3}}]}}}}`))
+			})
+			app := &App{
+				graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+				slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+			}
+			app.postDigest(context.Background(), "octocat", time.Date(2023, 1, 4, 9, 0, 0, 0, time.UTC), digestWeekly)
+			if len(got) != 1 || !strings.Contains(got[0], tt.want) {
+				t.Errorf("postDigest() posted %v, want to contain %v", got, tt.want)
+			}
+		})
+	}
+}
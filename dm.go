@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/slack-go/slack"
+)
+
+// Recipient is the Slack user a login's reports are sent to directly,
+// given by ID or looked up by email.
+type Recipient struct {
+	SlackUserID string `json:"slackUserId"`
+	SlackEmail  string `json:"slackEmail"`
+}
+
+// envRecipient is the recipient of GH_USER_NAME when no config file is used.
+func envRecipient() Recipient {
+	return Recipient{SlackUserID: os.Getenv("SLACK_USER_ID"), SlackEmail: os.Getenv("SLACK_USER_EMAIL")}
+}
+
+// channel returns where the reports of login go: a direct message when login
+// has a recipient, otherwise SLACK_CHANNEL_ID. A direct message that can not
+// be opened also falls back to SLACK_CHANNEL_ID so the report is not lost.
+func (a *App) channel(ctx context.Context, login string) string {
+	recipient := a.recipients[login]
+	if recipient.SlackUserID == "" && recipient.SlackEmail == "" {
+		return os.Getenv("SLACK_CHANNEL_ID")
+	}
+	if channel, ok := a.directMessages.Load(login); ok {
+		return channel.(string)
+	}
+	channel, err := a.slackClient.openDirectMessage(ctx, recipient)
+	if err != nil {
+		slog.Error("can not open direct message", "user", login, "err", err)
+		return os.Getenv("SLACK_CHANNEL_ID")
+	}
+	a.directMessages.Store(login, channel)
+	return channel
+}
+
+func (client SlackClient) openDirectMessage(ctx context.Context, recipient Recipient) (string, error) {
+	userID := recipient.SlackUserID
+	if userID == "" {
+		user, err := client.GetUserByEmailContext(ctx, recipient.SlackEmail)
+		if err != nil {
+			return "", fmt.Errorf("can not look up %s: %w", recipient.SlackEmail, err)
+		}
+		userID = user.ID
+	}
+	channel, _, _, err := client.OpenConversationContext(ctx, &slack.OpenConversationParameters{Users: []string{userID}})
+	if err != nil {
+		return "", fmt.Errorf("can not open conversation with %s: %w", userID, err)
+	}
+	return channel.ID, nil
+}
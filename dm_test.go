@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func TestChannel(t *testing.T) {
+	t.Setenv("SLACK_CHANNEL_ID", "C999")
+
+	tests := []struct {
+		name      string
+		recipient Recipient
+		want      string
+		wantCalls []string
+	}{
+		{
+			name:      "noRecipient",
+			recipient: Recipient{},
+			want:      "C999",
+			wantCalls: nil,
+		},
+		{
+			name:      "userID",
+			recipient: Recipient{SlackUserID: "U123"},
+			want:      "D123",
+			wantCalls: []string{"conversations.open users=U123"},
+		},
+		{
+			name:      "email",
+			recipient: Recipient{SlackEmail: "octocat@example.com"},
+			want:      "D123",
+			wantCalls: []string{"users.lookupByEmail email=octocat@example.com", "conversations.open users=U123"},
+		},
+		{
+			name:      "emailNotFound",
+			recipient: Recipient{SlackEmail: "hubot@example.com"},
+			want:      "C999",
+			wantCalls: []string{"users.lookupByEmail email=hubot@example.com", "users.lookupByEmail email=hubot@example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+				c.Handle("/users.lookupByEmail", func(w http.ResponseWriter, req *http.Request) {
+					req.ParseForm()
+					calls = append(calls, "users.lookupByEmail email="+req.PostForm.Get("email"))
+					if req.PostForm.Get("email") != "octocat@example.com" {
+						w.Write([]byte(`{"ok": false, "error": "users_not_found"}`))
+						return
+					}
+					w.Write([]byte(`{"ok": true, "user": {"id": "U123"}}`))
+				})
+				c.Handle("/conversations.open", func(w http.ResponseWriter, req *http.Request) {
+					req.ParseForm()
+					calls = append(calls, "conversations.open users="+req.PostForm.Get("users"))
+					w.Write([]byte(`{"ok": true, "channel": {"id": "D123"}}`))
+				})
+			})
+			ts.Start()
+			defer ts.Stop()
+
+			app := &App{
+				slackClient: SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+				recipients:  map[string]Recipient{"octocat": tt.recipient},
+			}
+			// the second call is answered from the cache once the direct message is open
+			for range 2 {
+				if got := app.channel(context.Background(), "octocat"); got != tt.want {
+					t.Errorf("channel() = %v, want %v", got, tt.want)
+				}
+			}
+			if len(calls) != len(tt.wantCalls) {
+				t.Fatalf("channel() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			for i := range calls {
+				if calls[i] != tt.wantCalls[i] {
+					t.Errorf("channel() calls[%d] = %v, want %v", i, calls[i], tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ExportRecord is a line of an export; Level and Weekday are only set when asked for.
+type ExportRecord struct {
+	Date              string `json:"date"`
+	ContributionCount int    `json:"contributionCount"`
+	Level             *int   `json:"level,omitempty"`
+	Weekday           string `json:"weekday,omitempty"`
+}
+
+// exportRecords levels days against the busiest of them, as the calendar is colored.
+func exportRecords(days []ContributionDay, withLevel bool, withWeekday bool) []ExportRecord {
+	scale := newLevelScale([]Week{{ContributionDays: days}})
+	records := make([]ExportRecord, 0, len(days))
+	for _, day := range days {
+		record := ExportRecord{Date: day.Date, ContributionCount: day.ContributionCount}
+		if withLevel {
+			level := scale.dayLevel(day)
+			record.Level = &level
+		}
+		if withWeekday {
+			if d, err := time.Parse("2006-01-02", day.Date); err == nil {
+				record.Weekday = d.Weekday().String()
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func writeCSV(w io.Writer, records []ExportRecord, withLevel bool, withWeekday bool) error {
+	out := csv.NewWriter(w)
+	header := []string{"date", "count"}
+	if withLevel {
+		header = append(header, "level")
+	}
+	if withWeekday {
+		header = append(header, "weekday")
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := []string{record.Date, strconv.Itoa(record.ContributionCount)}
+		if withLevel {
+			row = append(row, strconv.Itoa(*record.Level))
+		}
+		if withWeekday {
+			row = append(row, record.Weekday)
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeJSONL(w io.Writer, records []ExportRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// export dumps the calendar of a range for spreadsheets and notebooks.
+func (a *App) export(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	login := fs.String("login", os.Getenv("GH_USER_NAME"), "GitHub login to export")
+	format := fs.String("format", "csv", "output format: csv or jsonl")
+	output := fs.String("output", "-", "path to write to, - for stdout")
+	fromValue := fs.String("from", "", "first day to export as YYYY-MM-DD, a year before -to by default")
+	toValue := fs.String("to", "", "last day to export as YYYY-MM-DD, today by default")
+	input := fs.String("input", "", "path to a saved calendar to convert instead of asking GitHub")
+	withLevel := fs.Bool("level", false, "add the level of each day from 0 to 4")
+	withWeekday := fs.Bool("weekday", false, "add the weekday of each day")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("-login is required")
+	}
+	if *format != "csv" && *format != "jsonl" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	days, err := a.calendarDays(ctx, *login, *input, *fromValue, *toValue)
+	if err != nil {
+		return err
+	}
+	records := exportRecords(days, *withLevel, *withWeekday)
+	return writeFile(*output, func(w io.Writer) error {
+		if *format == "jsonl" {
+			return writeJSONL(w, records)
+		}
+		return writeCSV(w, records, *withLevel, *withWeekday)
+	})
+}
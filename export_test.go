@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+var exportDays = []ContributionDay{
+	{Date: "2023-01-01", ContributionCount: 0},
+	{Date: "2023-01-02", ContributionCount: 2},
+	{Date: "2023-01-03", ContributionCount: 8},
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		withLevel   bool
+		withWeekday bool
+		want        string
+	}{
+		{name: "plain", want: "date,count\n2023-01-01,0\n2023-01-02,2\n2023-01-03,8\n"},
+		{name: "level", withLevel: true, want: "date,count,level\n2023-01-01,0,0\n2023-01-02,2,1\n2023-01-03,8,4\n"},
+		{name: "all", withLevel: true, withWeekday: true, want: "date,count,level,weekday\n2023-01-01,0,0,Sunday\n2023-01-02,2,1,Monday\n2023-01-03,8,4,Tuesday\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := writeCSV(&b, exportRecords(exportDays, tt.withLevel, tt.withWeekday), tt.withLevel, tt.withWeekday); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeCSV() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	tests := []struct {
+		name        string
+		withLevel   bool
+		withWeekday bool
+		want        string
+	}{
+		{name: "plain", want: `{"date":"2023-01-01","contributionCount":0}` + "\n" + `{"date":"2023-01-02","contributionCount":2}` + "\n" + `{"date":"2023-01-03","contributionCount":8}` + "\n"},
+		{name: "all", withLevel: true, withWeekday: true, want: `{"date":"2023-01-01","contributionCount":0,"level":0,"weekday":"Sunday"}` + "\n" + `{"date":"2023-01-02","contributionCount":2,"level":1,"weekday":"Monday"}` + "\n" + `{"date":"2023-01-03","contributionCount":8,"level":4,"weekday":"Tuesday"}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := writeJSONL(&b, exportRecords(exportDays, tt.withLevel, tt.withWeekday)); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeJSONL() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(todayIsOneJson)
+	})
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		now:           func() time.Time { return time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC) },
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		want    string
+	}{
+		{name: "csv", args: []string{"-login", "octocat", "-from", "2023-01-03", "-output", filepath.Join(dir, "csv")}, want: "date,count\n2023-01-03,1\n"},
+		{name: "jsonl", args: []string{"-login", "octocat", "-from", "2023-01-03", "-format", "jsonl", "-weekday", "-output", filepath.Join(dir, "jsonl")}, want: `{"date":"2023-01-03","contributionCount":1,"weekday":"Tuesday"}` + "\n"},
+		{name: "unknownFormat", args: []string{"-login", "octocat", "-format", "xlsx"}, wantErr: `unknown format "xlsx"`},
+		{name: "noLogin", args: []string{"-login", ""}, wantErr: "-login is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := app.export(context.Background(), tt.args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("export() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("export() err = %v", err)
+			}
+			got, _ := os.ReadFile(filepath.Join(dir, tt.name))
+			if string(got) != tt.want {
+				t.Errorf("export() = %q, want %q", string(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// riskThreshold is the share of zero days from which a weekday is flagged
+	riskThreshold = 0.3
+	// riskObservations is how many of a weekday must be seen before it is judged
+	riskObservations = 4
+)
+
+var japaneseWeekdays = [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"}
+
+// Forecast estimates the chance of a day without contributions from how often each weekday had none.
+type Forecast [7]struct {
+	days  int
+	zeros int
+}
+
+type Risk struct {
+	date        time.Time
+	probability float64
+}
+
+func newForecast(days []ContributionDay) Forecast {
+	var f Forecast
+	for _, day := range days {
+		d, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		f[d.Weekday()].days++
+		if day.ContributionCount == 0 {
+			f[d.Weekday()].zeros++
+		}
+	}
+	return f
+}
+
+// probability returns the share of zero days on weekday, and false while there are too few to tell.
+func (f Forecast) probability(weekday time.Weekday) (float64, bool) {
+	if f[weekday].days < riskObservations {
+		return 0, false
+	}
+	return float64(f[weekday].zeros) / float64(f[weekday].days), true
+}
+
+// atRisk returns the days of the n from from that are likely to have no contributions.
+func (f Forecast) atRisk(from time.Time, n int) []Risk {
+	var risks []Risk
+	for i := range n {
+		d := from.AddDate(0, 0, i)
+		if p, ok := f.probability(d.Weekday()); ok && p >= riskThreshold {
+			risks = append(risks, Risk{date: d, probability: p})
+		}
+	}
+	return risks
+}
+
+func createRiskMessage(risks []Risk) string {
+	var lines []string
+	for _, r := range risks {
+		lines = append(lines, fmt.Sprintf("• %s(%s)は過去%.0f%%の確率でコミットしていません", r.date.Format("2006-01-02"), japaneseWeekdays[r.date.Weekday()], 100*r.probability))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// forecastDays are four weeks from Sunday 2023-01-01 skipping two Sundays and a Saturday
+func forecastDays() []ContributionDay {
+	skipped := map[string]bool{"2023-01-01": true, "2023-01-15": true, "2023-01-21": true}
+	var days []ContributionDay
+	for d := date("2023-01-01"); d.Before(date("2023-01-29")); d = d.AddDate(0, 0, 1) {
+		day := ContributionDay{Date: d.Format("2006-01-02"), ContributionCount: 1}
+		if skipped[day.Date] {
+			day.ContributionCount = 0
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func TestForecastProbability(t *testing.T) {
+	tests := []struct {
+		name    string
+		days    []ContributionDay
+		weekday time.Weekday
+		want    float64
+		wantOk  bool
+	}{
+		{name: "sunday", days: forecastDays(), weekday: time.Sunday, want: 0.5, wantOk: true},
+		{name: "saturday", days: forecastDays(), weekday: time.Saturday, want: 0.25, wantOk: true},
+		{name: "monday", days: forecastDays(), weekday: time.Monday, want: 0, wantOk: true},
+		{name: "tooFew", days: forecastDays()[:21], weekday: time.Sunday, want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newForecast(tt.days).probability(tt.weekday)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("probability() = %v %v, want %v %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestForecastAtRisk(t *testing.T) {
+	forecast := newForecast(forecastDays())
+
+	tests := []struct {
+		name string
+		from time.Time
+		n    int
+		want []Risk
+	}{
+		{name: "week", from: date("2023-01-29"), n: 7, want: []Risk{{date: date("2023-01-29"), probability: 0.5}}},
+		{name: "weekdays", from: date("2023-01-30"), n: 5, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forecast.atRisk(tt.from, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("atRisk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateRiskMessage(t *testing.T) {
+	risks := []Risk{{date: date("2023-01-29"), probability: 0.5}, {date: date("2023-02-04"), probability: 1.0 / 3}}
+	want := "• 2023-01-29(日曜日)は過去50%の確率でコミットしていません\n• 2023-02-04(土曜日)は過去33%の確率でコミットしていません"
+	if got := createRiskMessage(risks); got != want {
+		t.Errorf("createRiskMessage() = %v, want %v", got, want)
+	}
+}
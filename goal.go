@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	goalDaily  = "daily"
+	goalWeekly = "weekly"
+)
+
+// Goal is a number of contributions to reach in every day or week; weeks start on Sunday as on GitHub.
+type Goal struct {
+	Period string `json:"period"`
+	Target int    `json:"target"`
+}
+
+type GoalProgress struct {
+	goal  Goal
+	count int
+	// streak counts the periods in a row the goal was met, including the current one once it is met
+	streak int
+}
+
+// envGoals reads the goals of GH_USER_NAME from GOAL_DAILY and GOAL_WEEKLY.
+func envGoals() []Goal {
+	var goals []Goal
+	for _, goal := range []struct{ period, env string }{{goalDaily, "GOAL_DAILY"}, {goalWeekly, "GOAL_WEEKLY"}} {
+		target, err := strconv.Atoi(os.Getenv(goal.env))
+		if err != nil || target <= 0 {
+			continue
+		}
+		goals = append(goals, Goal{Period: goal.period, Target: target})
+	}
+	return goals
+}
+
+func (g Goal) validate() error {
+	if g.Period != goalDaily && g.Period != goalWeekly {
+		return fmt.Errorf("unknown goal period %q", g.Period)
+	}
+	if g.Target <= 0 {
+		return fmt.Errorf("goal target must be positive, got %d", g.Target)
+	}
+	return nil
+}
+
+// periodStart returns the first day of the period containing day.
+func (g Goal) periodStart(day time.Time) time.Time {
+	if g.Period == goalWeekly {
+		return day.AddDate(0, 0, -int(day.Weekday()))
+	}
+	return day
+}
+
+// goalProgress measures goal up to today over days, which bound how far back the streak is counted.
+func goalProgress(goal Goal, days []ContributionDay, today time.Time) GoalProgress {
+	if len(days) == 0 {
+		return GoalProgress{goal: goal}
+	}
+	first, _ := time.Parse("2006-01-02", days[0].Date)
+	counts := map[string]int{}
+	for _, day := range days {
+		counts[day.Date] = day.ContributionCount
+	}
+	sum := func(from time.Time, to time.Time) int {
+		n := 0
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			n += counts[d.Format("2006-01-02")]
+		}
+		return n
+	}
+
+	start := goal.periodStart(today)
+	progress := GoalProgress{goal: goal, count: sum(start, today)}
+	if progress.count >= goal.Target {
+		progress.streak++
+	}
+	for !start.Before(first) {
+		end := start.AddDate(0, 0, -1)
+		start = goal.periodStart(end)
+		if sum(start, end) < goal.Target {
+			break
+		}
+		progress.streak++
+	}
+	return progress
+}
+
+func createGoalMessage(progresses []GoalProgress) string {
+	var lines []string
+	for _, p := range progresses {
+		period, current, unit := "1日", "今日", "日"
+		if p.goal.Period == goalWeekly {
+			period, current, unit = "1週間", "今週", "週"
+		}
+		line := fmt.Sprintf("目標は%s%dコミット %sは%d/%d", period, p.goal.Target, current, p.count, p.goal.Target)
+		if p.count >= p.goal.Target {
+			line += " 達成！"
+		}
+		lines = append(lines, fmt.Sprintf("%s(連続達成は%d%s)", line, p.streak, unit))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// goalMessage reports the progress towards the goals of login over days, the ones its streak was counted from, or nothing when it has none.
+func (a *App) goalMessage(login string, today time.Time, days []ContributionDay) string {
+	goals := a.goals[login]
+	if len(goals) == 0 {
+		return ""
+	}
+	var progresses []GoalProgress
+	for _, goal := range goals {
+		progresses = append(progresses, goalProgress(goal, days, today))
+	}
+	return createGoalMessage(progresses)
+}
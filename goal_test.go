@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+// goalDays are the two weeks up to Wednesday 2023-01-04
+var goalDays = []ContributionDay{
+	{Date: "2022-12-25", ContributionCount: 5},
+	{Date: "2022-12-26", ContributionCount: 3},
+	{Date: "2022-12-27", ContributionCount: 3},
+	{Date: "2022-12-28", ContributionCount: 1},
+	{Date: "2022-12-29", ContributionCount: 3},
+	{Date: "2022-12-30", ContributionCount: 4},
+	{Date: "2022-12-31", ContributionCount: 3},
+	{Date: "2023-01-01", ContributionCount: 6},
+	{Date: "2023-01-02", ContributionCount: 3},
+	{Date: "2023-01-03", ContributionCount: 3},
+	{Date: "2023-01-04", ContributionCount: 2},
+}
+
+func TestGoalProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		goal  Goal
+		days  []ContributionDay
+		today string
+		want  GoalProgress
+	}{
+		{name: "dailyNotYetMet", goal: Goal{Period: goalDaily, Target: 3}, days: goalDays, today: "2023-01-04", want: GoalProgress{goal: Goal{Period: goalDaily, Target: 3}, count: 2, streak: 6}},
+		{name: "dailyMet", goal: Goal{Period: goalDaily, Target: 2}, days: goalDays, today: "2023-01-04", want: GoalProgress{goal: Goal{Period: goalDaily, Target: 2}, count: 2, streak: 7}},
+		{name: "weeklyMet", goal: Goal{Period: goalWeekly, Target: 14}, days: goalDays, today: "2023-01-04", want: GoalProgress{goal: Goal{Period: goalWeekly, Target: 14}, count: 14, streak: 2}},
+		{name: "weeklyNotYetMet", goal: Goal{Period: goalWeekly, Target: 20}, days: goalDays, today: "2023-01-04", want: GoalProgress{goal: Goal{Period: goalWeekly, Target: 20}, count: 14, streak: 1}},
+		{name: "noDays", goal: Goal{Period: goalDaily, Target: 1}, days: nil, today: "2023-01-04", want: GoalProgress{goal: Goal{Period: goalDaily, Target: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := goalProgress(tt.goal, tt.days, date(tt.today)); got != tt.want {
+				t.Errorf("goalProgress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateGoalMessage(t *testing.T) {
+	progresses := []GoalProgress{
+		{goal: Goal{Period: goalDaily, Target: 3}, count: 2, streak: 6},
+		{goal: Goal{Period: goalWeekly, Target: 14}, count: 14, streak: 2},
+	}
+	want := "目標は1日3コミット 今日は2/3(連続達成は6日)\n目標は1週間14コミット 今週は14/14 達成！(連続達成は2週)"
+	if got := createGoalMessage(progresses); got != want {
+		t.Errorf("createGoalMessage() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvGoals(t *testing.T) {
+	tests := []struct {
+		name   string
+		daily  string
+		weekly string
+		want   []Goal
+	}{
+		{name: "both", daily: "3", weekly: "20", want: []Goal{{Period: goalDaily, Target: 3}, {Period: goalWeekly, Target: 20}}},
+		{name: "weekly", daily: "", weekly: "20", want: []Goal{{Period: goalWeekly, Target: 20}}},
+		{name: "invalid", daily: "three", weekly: "0", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOAL_DAILY", tt.daily)
+			t.Setenv("GOAL_WEEKLY", tt.weekly)
+			if got := envGoals(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("envGoals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalMessage(t *testing.T) {
+	days := []ContributionDay{{Date: "2023-01-02", ContributionCount: 0}, {Date: "2023-01-03", ContributionCount: 1}}
+
+	tests := []struct {
+		name  string
+		goals []Goal
+		want  string
+	}{
+		{name: "goal", goals: []Goal{{Period: goalDaily, Target: 1}}, want: "目標は1日1コミット 今日は1/1 達成！(連続達成は1日)"},
+		{name: "notMet", goals: []Goal{{Period: goalDaily, Target: 2}}, want: "目標は1日2コミット 今日は1/2(連続達成は0日)"},
+		{name: "noGoals", goals: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{goals: map[string][]Goal{"octocat": tt.goals}}
+			if got := app.goalMessage("octocat", date("2023-01-03"), days); got != tt.want {
+				t.Errorf("goalMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunGoalsReuseCount(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	okJson, _ := testData.ReadFile("testdata/slack/ok.json")
+	var got []string
+	ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+		c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			got = append(got, req.PostForm.Get("text"))
+			w.Write(okJson)
+		})
+	})
+	ts.Start()
+	defer ts.Stop()
+
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Write(todayIsOneJson)
+	})
+	state, _ := loadState("")
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+		strategy:      "sequential",
+		state:         state,
+		goals:         map[string][]Goal{"octocat": {{Period: goalDaily, Target: 1}}},
+	}
+	app.run(context.Background(), "octocat", time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC))
+	if requests != 1 {
+		t.Errorf("run() requests = %v, want %v", requests, 1)
+	}
+	if len(got) != 1 || !strings.HasSuffix(got[0], "目標は1日1コミット 今日は1/1 達成！(連続達成は1日)") {
+		t.Errorf("run() posted %v, want the goal", got)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"log/slog"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	heatmapWeeks  = 53
+	heatmapCell   = 11
+	heatmapGap    = 2
+	heatmapMargin = 12
+)
+
+// heatmapColors are GitHub's light theme colors for levels 0 to 4, used for days GitHub gave no color.
+var heatmapColors = [5]color.RGBA{
+	{0xeb, 0xed, 0xf0, 0xff},
+	{0x9b, 0xe9, 0xa8, 0xff},
+	{0x40, 0xc4, 0x63, 0xff},
+	{0x30, 0xa1, 0x4e, 0xff},
+	{0x21, 0x6e, 0x39, 0xff},
+}
+
+func renderHeatmap(weeks []Week) *image.RGBA {
+	columns := calendarColumns(weeks, heatmapWeeks)
+	scale := newLevelScale(weeks)
+	step := heatmapCell + heatmapGap
+	img := image.NewRGBA(image.Rect(0, 0, heatmapMargin*2+len(columns)*step-heatmapGap, heatmapMargin*2+7*step-heatmapGap))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for x, column := range columns {
+		for y, day := range column {
+			if day == nil {
+				continue
+			}
+			cell := image.Rect(0, 0, heatmapCell, heatmapCell).Add(image.Pt(heatmapMargin+x*step, heatmapMargin+y*step))
+			draw.Draw(img, cell, image.NewUniform(scale.dayColor(*day)), image.Point{}, draw.Src)
+		}
+	}
+	return img
+}
+
+func encodeHeatmap(w io.Writer, weeks []Week) error {
+	return png.Encode(w, renderHeatmap(weeks))
+}
+
+// postHeatmap uploads the heatmap of the year up to today next to the report in channel.
+func (a *App) postHeatmap(ctx context.Context, result *Result, channel string) {
+	weeks, err := Client{a.graphqlClient}.fetchWeeks(ctx, result.userName, result.today.AddDate(0, 0, -365), result.today)
+	if err != nil {
+		slog.Error("can not fetch calendar", "user", result.userName, "err", err)
+		return
+	}
+	var buf bytes.Buffer
+	if err := encodeHeatmap(&buf, weeks); err != nil {
+		slog.Error("can not render heatmap", "user", result.userName, "err", err)
+		return
+	}
+	a.slackClient.uploadSlack(ctx, channel, slack.UploadFileV2Parameters{
+		Reader:   &buf,
+		FileSize: buf.Len(),
+		Filename: result.userName + "-" + result.today.Format("2006-01-02") + ".png",
+		Title:    result.userName + "のコントリビューション",
+		AltTxt:   result.userName + "の過去1年間のコントリビューションのヒートマップ",
+	})
+}
+
+func (client SlackClient) uploadSlack(ctx context.Context, channel string, params slack.UploadFileV2Parameters) {
+	params.Channel = channel
+	file, err := client.UploadFileV2Context(ctx, params)
+	metrics.observeSlack(err)
+	if err != nil {
+		slog.Error("can not upload file", "err", err)
+		return
+	}
+	slog.Info("uploaded file", "channel", channel, "file", file.ID)
+}
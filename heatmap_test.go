@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func TestRenderHeatmap(t *testing.T) {
+	weeks := []Week{
+		{ContributionDays: []ContributionDay{{ContributionCount: 0, Date: "2023-01-01"}, {ContributionCount: 1, Date: "2023-01-02"}, {ContributionCount: 8, Date: "2023-01-03"}}},
+	}
+	img := renderHeatmap(weeks)
+	if got := img.Bounds().Dx(); got != heatmapMargin*2+heatmapCell {
+		t.Errorf("renderHeatmap() width = %v", got)
+	}
+	if got := img.Bounds().Dy(); got != heatmapMargin*2+7*(heatmapCell+heatmapGap)-heatmapGap {
+		t.Errorf("renderHeatmap() height = %v", got)
+	}
+	step := heatmapCell + heatmapGap
+	tests := []struct {
+		name string
+		row  int
+		want [4]uint8
+	}{
+		{name: "sundayIsZero", row: 0, want: [4]uint8{0xeb, 0xed, 0xf0, 0xff}},
+		{name: "mondayIsLow", row: 1, want: [4]uint8{0x9b, 0xe9, 0xa8, 0xff}},
+		{name: "tuesdayIsHigh", row: 2, want: [4]uint8{0x21, 0x6e, 0x39, 0xff}},
+		{name: "wednesdayIsOutside", row: 3, want: [4]uint8{0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := img.RGBAAt(heatmapMargin+1, heatmapMargin+tt.row*step+1)
+			if got := [4]uint8{c.R, c.G, c.B, c.A}; got != tt.want {
+				t.Errorf("renderHeatmap() color = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostHeatmap(t *testing.T) {
+	allOneJson, _ := testData.ReadFile("testdata/CountOverAYear/allOne.json")
+
+	var uploaded []byte
+	var completed string
+	ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+		c.Handle("/files.getUploadURLExternal", func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte(`{"ok": true, "upload_url": "http://` + req.Host + `/upload", "file_id": "F123"}`))
+		})
+		c.Handle("/upload", func(w http.ResponseWriter, req *http.Request) {
+			file, _, err := req.FormFile("file")
+			if err == nil {
+				uploaded, _ = io.ReadAll(file)
+			}
+		})
+		c.Handle("/files.completeUploadExternal", func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			completed = req.PostForm.Get("channel_id")
+			w.Write([]byte(`{"ok": true, "files": [{"id": "F123"}]}`))
+		})
+	})
+	ts.Start()
+	defer ts.Stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(allOneJson)
+	})
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+	}
+	app.postHeatmap(context.Background(), &Result{userName: "octocat", today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)}, "C123")
+
+	if completed != "C123" {
+		t.Errorf("postHeatmap() channel = %v, want C123", completed)
+	}
+	img, err := png.Decode(bytes.NewReader(uploaded))
+	if err != nil {
+		t.Fatalf("postHeatmap() uploaded %v bytes that are not a PNG: %v", len(uploaded), err)
+	}
+	if got := img.Bounds().Dx(); got != heatmapMargin*2+53*(heatmapCell+heatmapGap)-heatmapGap {
+		t.Errorf("postHeatmap() width = %v", got)
+	}
+}
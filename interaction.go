@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const snoozeActionID = "snooze_today"
+
+// interaction handles the button messageOptions attaches: it stops the
+// reminders of that login for the day and replaces the button with who pressed it.
+func (api *API) interaction(w http.ResponseWriter, req *http.Request) {
+	if err := api.verifySlackRequest(req); err != nil {
+		slog.Warn("can not verify slack request", "err", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(req.FormValue("payload")), &callback); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, action := range callback.ActionCallback.BlockActions {
+		if action.ActionID != snoozeActionID {
+			continue
+		}
+		login, value, _ := strings.Cut(action.Value, "/")
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := api.app.state.snooze(login, day); err != nil {
+			slog.Error("can not snooze", "user", login, "err", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		slog.Info("snoozed reminders", "user", login, "day", value, "slackUser", callback.User.ID)
+		message := &slack.WebhookMessage{ReplaceOriginal: true, Text: fmt.Sprintf("%s\n<@%s>さんが今日の通知を止めました", callback.Message.Text, callback.User.ID)}
+		if err := slack.PostWebhookContext(req.Context(), callback.ResponseURL, message); err != nil {
+			slog.Error("can not update message", "user", login, "err", err)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
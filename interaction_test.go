@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func TestInteractionSnoozesReminders(t *testing.T) {
+	todayAndYesterdayAreZeroJson, _ := testData.ReadFile("testdata/CountOverAYear/todayAndYesterdayAreZero.json")
+	okJson, _ := testData.ReadFile("testdata/slack/ok.json")
+
+	var posted []url.Values
+	var updated slack.WebhookMessage
+	ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+		c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			posted = append(posted, req.PostForm)
+			w.Write(okJson)
+		})
+		c.Handle("/response", func(w http.ResponseWriter, req *http.Request) {
+			json.NewDecoder(req.Body).Decode(&updated)
+		})
+	})
+	ts.Start()
+	defer ts.Stop()
+
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(todayAndYesterdayAreZeroJson)
+	})
+	api.signingSecret = "signingSecret"
+	api.app.slackClient = SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))}
+	api.app.snoozeButton = true
+	now := time.Date(2023, 1, 3, 18, 0, 0, 0, time.UTC)
+
+	api.app.run(context.Background(), "octocat", now)
+	if len(posted) != 1 {
+		t.Fatalf("run() posted %v messages, want 1", len(posted))
+	}
+	var blocks slack.Blocks
+	json.Unmarshal([]byte(posted[0].Get("blocks")), &blocks)
+	if len(blocks.BlockSet) != 2 {
+		t.Fatalf("run() blocks = %v, want a section and a button", posted[0].Get("blocks"))
+	}
+	button := blocks.BlockSet[1].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement)
+	if button.ActionID != snoozeActionID || button.Value != "octocat/2023-01-03" {
+		t.Errorf("run() button = %v %v, want %v octocat/2023-01-03", button.ActionID, button.Value, snoozeActionID)
+	}
+
+	payload, _ := json.Marshal(slack.InteractionCallback{
+		Type:        slack.InteractionTypeBlockActions,
+		User:        slack.User{ID: "U123"},
+		ResponseURL: ts.GetAPIURL() + "response",
+		Message:     slack.Message{Msg: slack.Msg{Text: "<!channel> 今日はまだコミットしていません！"}},
+		ActionCallback: slack.ActionCallbacks{BlockActions: []*slack.BlockAction{
+			{ActionID: snoozeActionID, Value: button.Value},
+		}},
+	})
+	rec := httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, signedSlackRequest("/slack/interactions", "signingSecret", url.Values{"payload": {string(payload)}}.Encode()))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("interaction() status = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if !updated.ReplaceOriginal || !strings.HasSuffix(updated.Text, "<@U123>さんが今日の通知を止めました") {
+		t.Errorf("interaction() updated = %+v", updated)
+	}
+
+	api.app.run(context.Background(), "octocat", now.Add(3*time.Hour))
+	if len(posted) != 1 {
+		t.Errorf("run() posted %v messages after snooze, want 1", len(posted))
+	}
+	api.app.run(context.Background(), "octocat", now.AddDate(0, 0, 1))
+	if len(posted) != 2 {
+		t.Errorf("run() posted %v messages the next day, want 2", len(posted))
+	}
+}
+
+func TestRunWithoutInteractions(t *testing.T) {
+	todayAndYesterdayAreZeroJson, _ := testData.ReadFile("testdata/CountOverAYear/todayAndYesterdayAreZero.json")
+	okJson, _ := testData.ReadFile("testdata/slack/ok.json")
+
+	var posted []url.Values
+	ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+		c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			posted = append(posted, req.PostForm)
+			w.Write(okJson)
+		})
+	})
+	ts.Start()
+	defer ts.Stop()
+
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(todayAndYesterdayAreZeroJson)
+	})
+	api.app.slackClient = SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))}
+
+	api.app.run(context.Background(), "octocat", time.Date(2023, 1, 3, 18, 0, 0, 0, time.UTC))
+	if len(posted) != 1 {
+		t.Fatalf("run() posted %v messages, want 1", len(posted))
+	}
+	if blocks := posted[0].Get("blocks"); blocks != "" {
+		t.Errorf("run() blocks = %v, want no button", blocks)
+	}
+}
+
+func TestInteractionInvalidSignature(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {})
+	api.signingSecret = "signingSecret"
+	rec := httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, signedSlackRequest("/slack/interactions", "wrongSecret", "payload=%7B%7D"))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("interaction() status = %v, want %v", rec.Code, http.StatusUnauthorized)
+	}
+}
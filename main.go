@@ -2,9 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/shurcooL/githubv4"
@@ -13,8 +20,12 @@ import (
 )
 
 type ContributionDay struct {
-	ContributionCount int
-	Date              string
+	ContributionCount int    `json:"contributionCount"`
+	Date              string `json:"date"`
+	// ContributionLevel is GitHub's quartile of the day, e.g. FIRST_QUARTILE; empty for days read from older exports
+	ContributionLevel string `json:"contributionLevel,omitempty"`
+	Color             string `json:"color,omitempty"`
+	Weekday           int    `json:"weekday"`
 }
 
 type Week struct {
@@ -37,6 +48,13 @@ type Query struct {
 	User User `graphql:"user(login: $name)"`
 }
 
+const (
+	defaultFetchConcurrency = 4
+	defaultTimeout          = 5 * time.Minute
+	slackErrorTimeout       = 10 * time.Second
+	webhookTimeout          = 10 * time.Second
+)
+
 type Client struct {
 	*githubv4.Client
 }
@@ -53,49 +71,345 @@ type Result struct {
 	total                  int
 	streak                 int
 	isContinue             bool
+	// counted collects the days of every window counted when it is set, so they can be stored and measured against goals
+	counted *[]ContributionDay
+}
+
+type App struct {
+	graphqlClient *githubv4.Client
+	slackClient   SlackClient
+	strategy      string
+	state         *State
+	// messageMode and messageScope choose how runs share a Slack message, see postReport
+	messageMode  string
+	messageScope string
+	// recipients are the logins reported by direct message instead of to SLACK_CHANNEL_ID
+	recipients     map[string]Recipient
+	directMessages sync.Map
+	// goals are reported with the daily report of each login
+	goals map[string][]Goal
+	// snoozeButton is set while serve answers Slack interactions, which the button needs
+	snoozeButton bool
+	// heatmap uploads a PNG of the last year with each new daily report
+	heatmap bool
+	// store keeps history in SQLite when DATABASE_FILE is set
+	store *Store
+	now   func() time.Time
 }
 
 func main() {
-	userName := os.Getenv("GH_USER_NAME")
+	slog.SetDefault(newLogger(os.Stderr, os.Getenv("LOG_FORMAT"), false))
+	flag.Var(verboseFlag{}, "verbose", "log each fetched window")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := newApp(ctx)
+	if app.store != nil {
+		defer app.store.Close()
+	}
+	switch flag.Arg(0) {
+	case "serve":
+		if err := app.serve(ctx, flag.Args()[1:]); err != nil {
+			slog.Error("can not serve", "err", err)
+			os.Exit(1)
+		}
+	case "svg":
+		if err := app.svg(ctx, flag.Args()[1:]); err != nil {
+			slog.Error("can not write svg", "err", err)
+			os.Exit(1)
+		}
+	case "streak":
+		if err := app.streakCommand(ctx, flag.Args()[1:], os.Stdout); err != nil {
+			slog.Error("can not count streak", "err", err)
+			os.Exit(1)
+		}
+	case "stats":
+		if err := app.stats(ctx, flag.Args()[1:], os.Stdout); err != nil {
+			slog.Error("can not compute stats", "err", err)
+			os.Exit(1)
+		}
+	case "digest":
+		if err := app.digestCommand(ctx, flag.Args()[1:]); err != nil {
+			slog.Error("can not post digest", "err", err)
+			os.Exit(1)
+		}
+	case "review":
+		if err := app.review(ctx, flag.Args()[1:]); err != nil {
+			slog.Error("can not review year", "err", err)
+			os.Exit(1)
+		}
+	case "export":
+		if err := app.export(ctx, flag.Args()[1:]); err != nil {
+			slog.Error("can not export", "err", err)
+			os.Exit(1)
+		}
+	default:
+		app.run(ctx, os.Getenv("GH_USER_NAME"), app.now())
+	}
+}
+
+func newApp(ctx context.Context) *App {
 	src := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: os.Getenv("GH_TOKEN")},
 	)
-	httpClient := oauth2.NewClient(context.Background(), src)
-	graphqlClient := githubv4.NewClient(httpClient)
-
-	now := time.Now()
+	httpClient := oauth2.NewClient(ctx, src)
+	state, err := loadState(os.Getenv("STATE_FILE"))
+	if err != nil {
+		slog.Error("can not load state", "path", os.Getenv("STATE_FILE"), "err", err)
+	}
+	var store *Store
+	if path := os.Getenv("DATABASE_FILE"); path != "" {
+		if store, err = openStore(ctx, path); err != nil {
+			slog.Error("can not open store", "path", path, "err", err)
+		}
+	}
+	return &App{
+		store:         store,
+		graphqlClient: githubv4.NewClient(httpClient),
+		slackClient:   SlackClient{slack.New(os.Getenv("SLACK_BOT_TOKEN"))},
+		strategy:      os.Getenv("GH_FETCH_STRATEGY"),
+		state:         state,
+		messageMode:   os.Getenv("SLACK_MESSAGE_MODE"),
+		messageScope:  os.Getenv("SLACK_MESSAGE_SCOPE"),
+		heatmap:       os.Getenv("SLACK_HEATMAP") == "true",
+		now:           time.Now,
+		recipients: map[string]Recipient{
+			os.Getenv("GH_USER_NAME"): envRecipient(),
+		},
+		goals: map[string][]Goal{os.Getenv("GH_USER_NAME"): envGoals()},
+	}
+}
 
+// newResult starts counting from the date of now in its own location.
+func newResult(userName string, now time.Time) Result {
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return Result{userName: userName, todayContributionCount: 0, today: today, latestDay: today.AddDate(0, 0, 1), total: 0, streak: 0, isContinue: true}
+}
 
-	result := Result{userName: userName, todayContributionCount: 0, today: today, latestDay: today.AddDate(0, 0, 1), total: 0, streak: 0, isContinue: true}
+func (a *App) run(ctx context.Context, userName string, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
 
-	slackClient := SlackClient{slack.New(os.Getenv("SLACK_BOT_TOKEN"))}
-	if err := result.countOverAYear(graphqlClient); err != nil {
-		slackClient.postSlackError()
+	result, err := a.streak(ctx, userName, now)
+	if err != nil {
+		// shutting down is not worth telling the channel about
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if a.reportLastKnown(ctx, userName, now) {
+			return
+		}
+		// ctx may already be done, so the error report gets its own short deadline
+		errCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slackErrorTimeout)
+		defer cancel()
+		a.slackClient.postSlackError(errCtx)
 		return
 	}
 
+	if result.todayContributionCount == 0 && a.state.isSnoozed(userName, result.today) {
+		slog.Info("reminder is snoozed", "user", userName)
+		return
+	}
 	message := result.createMessage()
-	slackClient.postSlack(message)
+	if goals := a.goalMessage(userName, result.today, result.countedDays()); goals != "" {
+		message += "\n" + goals
+	}
+	posted := a.postReport(ctx, &result, message, a.messageOptions(&result, message))
+	if a.heatmap && posted.TS != "" {
+		a.postHeatmap(ctx, &result, posted.Channel)
+	}
+}
+
+// streak counts the streak of userName up to the date of now and records it in the metrics,
+// and with the days it was counted from in the store.
+func (a *App) streak(ctx context.Context, userName string, now time.Time) (Result, error) {
+	result := newResult(userName, now)
+	var counted []ContributionDay
+	result.counted = &counted
+	if err := result.count(ctx, a.graphqlClient, a.strategy); err != nil {
+		slog.Error("can not count commits", "user", userName, "err", err)
+		return result, err
+	}
+	slog.Info("counted commits", "user", userName, "streak", result.streak, "total", result.total, "today", result.todayContributionCount, "from", result.latestDay.Format("2006-01-02"))
+	metrics.observeResult(&result)
+	if a.store != nil {
+		if err := a.store.saveResult(ctx, &result, now); err != nil {
+			slog.Error("can not store result", "user", userName, "err", err)
+		}
+		if err := a.store.saveDays(ctx, userName, result.countedDays()); err != nil {
+			slog.Error("can not store calendar", "user", userName, "err", err)
+		}
+	}
+	if path := os.Getenv("METRICS_TEXTFILE"); path != "" {
+		if err := metrics.writeTextfile(path); err != nil {
+			slog.Error("can not write metrics", "path", path, "err", err)
+		}
+	}
+	return result, nil
+}
+
+func newLogger(w io.Writer, format string, verbose bool) *slog.Logger {
+	options := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if verbose {
+		options.Level = slog.LevelDebug
+	}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// verboseFlag switches the default logger to debug level as soon as -verbose is parsed.
+type verboseFlag struct{}
+
+func (verboseFlag) IsBoolFlag() bool { return true }
+
+func (verboseFlag) String() string { return "false" }
+
+func (verboseFlag) Set(s string) error {
+	verbose, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(newLogger(os.Stderr, os.Getenv("LOG_FORMAT"), verbose))
+	return nil
+}
+
+// newFlagSet is the FlagSet of a subcommand, which accepts -verbose after the subcommand as well as before it.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Var(verboseFlag{}, "verbose", "log each fetched window")
+	return fs
+}
+
+func timeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("COUNT_COMMITS_TIMEOUT"))
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return timeout
 }
 
-func (r *Result) countOverAYear(graphqlClient *githubv4.Client) error {
+func fetchConcurrency() int {
+	concurrency, err := strconv.Atoi(os.Getenv("GH_FETCH_CONCURRENCY"))
+	if err != nil || concurrency < 1 {
+		return defaultFetchConcurrency
+	}
+	return concurrency
+}
+
+func (r *Result) variables(to time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"name": githubv4.String(r.userName),
+		"from": githubv4.DateTime{Time: to.AddDate(0, 0, -365)},
+		"to":   githubv4.DateTime{Time: to},
+	}
+}
+
+func (r *Result) count(ctx context.Context, graphqlClient *githubv4.Client, strategy string) error {
+	switch strategy {
+	case "sequential":
+		return r.countOverAYear(ctx, graphqlClient)
+	case "concurrent":
+		return r.countOverAYearConcurrently(ctx, graphqlClient, fetchConcurrency())
+	default:
+		return r.countOverAYearBatched(ctx, graphqlClient, batchWindows())
+	}
+}
+
+func (r *Result) countOverAYear(ctx context.Context, graphqlClient *githubv4.Client) error {
 	for i := 0; r.isContinue; i++ {
-		from := githubv4.DateTime{Time: r.latestDay.AddDate(0, 0, -365)}
-		to := githubv4.DateTime{Time: r.latestDay.AddDate(0, 0, 0)}
-		variables := map[string]interface{}{
-			"name": githubv4.String(r.userName),
-			"from": githubv4.DateTime(from),
-			"to":   githubv4.DateTime(to),
-		}
-		query := Client{graphqlClient}.execQuery(context.Background(), variables)
-		if err := r.countCommittedDays(query); err != nil {
+		query, err := Client{graphqlClient}.execQuery(ctx, r.variables(r.latestDay))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.countWindow(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countOverAYearConcurrently gives the same result as countOverAYear.
+// The first window is fetched alone because most streaks end inside it; once
+// the streak is known to reach further back, the following windows are
+// fetched concurrency at a time and counted in order.
+func (r *Result) countOverAYearConcurrently(ctx context.Context, graphqlClient *githubv4.Client, concurrency int) error {
+	client := Client{graphqlClient}
+	query, err := client.execQuery(ctx, r.variables(r.latestDay))
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.countWindow(query); err != nil {
+		return err
+	}
+	for r.isContinue {
+		start := r.latestDay
+		queries := make([]Query, concurrency)
+		errs := make([]error, concurrency)
+		var wg sync.WaitGroup
+		for i := range queries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				queries[i], errs[i] = client.execQuery(ctx, r.variables(start.AddDate(0, 0, -365*i)))
+			}()
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.countWindows(start, queries, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countWindows counts queries fetched for consecutive windows ending at start,
+// start-365 days and so on, in the same order as countOverAYear would. errs
+// holds the error of fetching each window, if any; it only fails the count
+// once the streak reaches that window.
+func (r *Result) countWindows(start time.Time, queries []Query, errs []error) error {
+	for i, query := range queries {
+		// a window that did not end where the next one starts leaves a gap, so refetch from latestDay
+		if !r.isContinue || !r.latestDay.Equal(start.AddDate(0, 0, -365*i)) {
+			return nil
+		}
+		if i < len(errs) && errs[i] != nil {
+			return errs[i]
+		}
+		if err := r.countWindow(query); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// countWindow counts query like countCommittedDays, but fails when the window
+// did not reach back any further, which happens when GitHub returns no calendar.
+func (r *Result) countWindow(query Query) error {
+	latestDay := r.latestDay
+	if err := r.countCommittedDays(query); err != nil {
+		return err
+	}
+	if r.counted != nil {
+		*r.counted = append(*r.counted, query.days()...)
+	}
+	if r.isContinue && r.latestDay.Equal(latestDay) {
+		return fmt.Errorf("no contributions are returned before %s", latestDay.Format("2006-01-02"))
+	}
+	return nil
+}
+
 func (r *Result) countCommittedDays(query Query) error {
 	weeksLength := len(query.User.ContributionsCollection.ContributionCalendar.Weeks)
 	for i := weeksLength - 1; i >= 0; i-- {
@@ -130,13 +444,16 @@ func (r *Result) countCommittedDays(query Query) error {
 	return nil
 }
 
-func (client Client) execQuery(ctx context.Context, variables map[string]interface{}) Query {
+func (client Client) execQuery(ctx context.Context, variables map[string]interface{}) (Query, error) {
 	var query Query
+	start := time.Now()
 	graphqlErr := client.Query(ctx, &query, variables)
+	metrics.observeQuery(time.Since(start), graphqlErr)
 	if graphqlErr != nil {
-		log.Println("query is error.", graphqlErr)
+		slog.Error("query is error", "user", variables["name"], "err", graphqlErr)
 	}
-	return query
+	slog.Debug("fetched window", "user", variables["name"], "from", variables["from"], "to", variables["to"], "duration", time.Since(start))
+	return query, graphqlErr
 }
 
 func (r *Result) createMessage() string {
@@ -146,26 +463,68 @@ func (r *Result) createMessage() string {
 	} else {
 		message = fmt.Sprintf("\n今日のコミット数は%d", r.todayContributionCount)
 	}
-	var average float64
+	message += fmt.Sprintf("\n連続コミット日数は%d\n合計コミット数は%d\n平均コミット数は%f\n期間は%s ~\nhttps://github.com/%s", r.streak, r.total, r.average(), r.latestDay.Format("2006-01-02"), r.userName)
+	return message
+}
+
+// createMessageOptions attaches a button to stop today's reminders when message warns that nothing is committed yet.
+func (r *Result) createMessageOptions(message string) []slack.MsgOption {
+	if r.todayContributionCount != 0 {
+		return nil
+	}
+	return []slack.MsgOption{slack.MsgOptionBlocks(
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, message, false, false), nil, nil),
+		slack.NewActionBlock("", slack.NewButtonBlockElement(snoozeActionID, r.userName+"/"+r.today.Format("2006-01-02"), slack.NewTextBlockObject(slack.PlainTextType, "今日はもう通知しない", false, false))),
+	)}
+}
+
+// messageOptions leaves the snooze button out when no endpoint would handle the click.
+func (a *App) messageOptions(result *Result, message string) []slack.MsgOption {
+	if !a.snoozeButton {
+		return nil
+	}
+	return result.createMessageOptions(message)
+}
+
+// countedDays returns the days of the windows counted, each once and in order.
+func (r *Result) countedDays() []ContributionDay {
+	if r.counted == nil {
+		return nil
+	}
+	merged := map[string]ContributionDay{}
+	for _, day := range *r.counted {
+		merged[day.Date] = day
+	}
+	return sortedDays(merged)
+}
+
+func (r *Result) average() float64 {
 	if r.streak == 0 {
-		average = 0
-	} else {
-		average = float64(r.total) / float64(r.streak)
+		return 0
 	}
-	message += fmt.Sprintf("\n連続コミット日数は%d\n合計コミット数は%d\n平均コミット数は%f\n期間は%s ~\nhttps://github.com/%s", r.streak, r.total, average, r.latestDay.Format("2006-01-02"), r.userName)
-	return message
+	return float64(r.total) / float64(r.streak)
+}
+
+func (client SlackClient) postSlack(ctx context.Context, message string, options ...slack.MsgOption) (string, string) {
+	return client.postSlackTo(ctx, os.Getenv("SLACK_CHANNEL_ID"), message, options...)
 }
 
-func (client SlackClient) postSlack(message string) {
-	_, _, err := client.PostMessage(os.Getenv("SLACK_CHANNEL_ID"), slack.MsgOptionText(message, false))
+func (client SlackClient) postSlackTo(ctx context.Context, channelID string, message string, options ...slack.MsgOption) (string, string) {
+	options = append([]slack.MsgOption{slack.MsgOptionText(message, false)}, options...)
+	channel, ts, err := client.PostMessageContext(ctx, channelID, options...)
+	metrics.observeSlack(err)
 	if err != nil {
-		log.Println("can not post message.", err)
+		slog.Error("can not post message", "err", err)
+		return "", ""
 	}
+	slog.Info("posted message", "channel", channel, "ts", ts)
+	return channel, ts
 }
 
-func (client SlackClient) postSlackError() {
-	_, _, err := client.PostMessage(os.Getenv("SLACK_CHANNEL_ID"), slack.MsgOptionText("<!channel> count-commits-js error", false))
+func (client SlackClient) postSlackError(ctx context.Context) {
+	_, _, err := client.PostMessageContext(ctx, os.Getenv("SLACK_CHANNEL_ID"), slack.MsgOptionText("<!channel> count-commits-js error", false))
+	metrics.observeSlack(err)
 	if err != nil {
-		log.Println("can not post message.", err)
+		slog.Error("can not post message", "err", err)
 	}
 }
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -94,7 +98,7 @@ func TestCountOverAYear(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			log.Println(tt.name)
 			r := &Result{userName: tt.arg, today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), isContinue: true}
-			err := r.countOverAYear(client)
+			err := r.countOverAYear(context.Background(), client)
 			if err != nil {
 				t.Errorf("countOverAYear() err = %v", err)
 			}
@@ -117,6 +121,149 @@ func TestCountOverAYear(t *testing.T) {
 	}
 }
 
+func TestCountOverAYearConcurrently(t *testing.T) {
+	emptyJson, _ := testData.ReadFile("testdata/ExecQuery/queryIsNil.json")
+	todayAndYesterDayArezeroJson, _ := testData.ReadFile("testdata/CountOverAYear/todayAndYesterdayAreZero.json")
+	todayIsZeroYesterdayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsZeroYesterdayIsOne.json")
+	allOneJson, _ := testData.ReadFile("testdata/CountOverAYear/allOne.json")
+	minusOneYearJson, _ := testData.ReadFile("testdata/CountOverAYear/minusOneYear.json")
+	minusOneYearStartsWithZeroJson, _ := testData.ReadFile("testdata/CountOverAYear/minusOneYearStartsWithZero.json")
+
+	tests := []struct {
+		name        string
+		concurrency int
+		windows     map[string][]byte
+	}{
+		{
+			name:        "todayAndYesterdayAreZero",
+			concurrency: 4,
+			windows:     map[string][]byte{"2023-01-04": todayAndYesterDayArezeroJson},
+		},
+		{
+			name:        "todayIsZeroYesterdayIsOne",
+			concurrency: 4,
+			windows:     map[string][]byte{"2023-01-04": todayIsZeroYesterdayIsOneJson},
+		},
+		{
+			name:        "overAYearNotConsecutive",
+			concurrency: 4,
+			windows:     map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearStartsWithZeroJson},
+		},
+		{
+			name:        "overAYearConsecutive",
+			concurrency: 4,
+			windows:     map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearJson},
+		},
+		{
+			name:        "overAYearConsecutiveConcurrencyIsOne",
+			concurrency: 1,
+			windows:     map[string][]byte{"2023-01-04": allOneJson, "2022-01-04": minusOneYearJson},
+		},
+	}
+	for _, tt := range tests {
+		mux := http.NewServeMux()
+		client := githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			var body struct {
+				Variables struct {
+					To time.Time `json:"to"`
+				} `json:"variables"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			if res, ok := tt.windows[body.Variables.To.Format("2006-01-02")]; ok {
+				w.Write(res)
+				return
+			}
+			w.Write(emptyJson)
+		})
+		t.Run(tt.name, func(t *testing.T) {
+			want := &Result{userName: "octocat", today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), isContinue: true}
+			if err := want.countOverAYear(context.Background(), client); err != nil {
+				t.Fatalf("countOverAYear() err = %v", err)
+			}
+			r := &Result{userName: "octocat", today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), isContinue: true}
+			if err := r.countOverAYearConcurrently(context.Background(), client, tt.concurrency); err != nil {
+				t.Errorf("countOverAYearConcurrently() err = %v", err)
+			}
+			if *r != *want {
+				t.Errorf("countOverAYearConcurrently() = %+v, want %+v", *r, *want)
+			}
+		})
+	}
+}
+
+func TestCountGitHubError(t *testing.T) {
+	allOneJson, _ := testData.ReadFile("testdata/CountOverAYear/allOne.json")
+	mux := http.NewServeMux()
+	client := githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})
+	// the first window is fine, every window before it fails
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Variables map[string]time.Time `json:"variables"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		if to, ok := body.Variables["to"]; ok && to.Equal(time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC)) {
+			w.Write(allOneJson)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	for _, strategy := range []string{"sequential", "concurrent", "batched"} {
+		t.Run(strategy, func(t *testing.T) {
+			r := newResult("octocat", time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC))
+			err := r.count(context.Background(), client, strategy)
+			if err == nil || !strings.Contains(err.Error(), "502 Bad Gateway") {
+				t.Errorf("count() err = %v, want the GitHub error", err)
+			}
+		})
+	}
+}
+
+func TestCountCanceled(t *testing.T) {
+	allOneJson, _ := testData.ReadFile("testdata/CountOverAYear/allOne.json")
+	emptyJson, _ := testData.ReadFile("testdata/ExecQuery/queryIsNil.json")
+
+	tests := []struct {
+		name     string
+		strategy string
+	}{
+		{name: "sequential", strategy: "sequential"},
+		{name: "concurrent", strategy: "concurrent"},
+		{name: "batched", strategy: ""},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		mux := http.NewServeMux()
+		client := githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})
+		var requests atomic.Int32
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			if requests.Add(1) == 1 && strings.Contains(string(body), "w0:") {
+				// a batch of one window, the first year
+				w.Write(bytes.Replace(allOneJson, []byte(`"contributionsCollection"`), []byte(`"w0"`), 1))
+			} else if requests.Load() == 1 {
+				w.Write(allOneJson)
+			} else {
+				// the job is interrupted while the older windows are being fetched
+				cancel()
+				w.Write(emptyJson)
+			}
+		})
+		t.Run(tt.name, func(t *testing.T) {
+			defer cancel()
+			t.Setenv("GH_BATCH_WINDOWS", "1")
+			r := &Result{userName: "octocat", today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), isContinue: true}
+			err := r.count(ctx, client, tt.strategy)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("count() err = %v, want %v", err, context.Canceled)
+			}
+			if r.streak != 365 {
+				t.Errorf("count() streak = %v, want %v", r.streak, 365)
+			}
+		})
+	}
+}
+
 func TestExecQuery(t *testing.T) {
 	type args struct {
 		ctx       context.Context
@@ -156,7 +303,7 @@ func TestExecQuery(t *testing.T) {
 			w.Write(res)
 		})
 		t.Run(tt.name, func(t *testing.T) {
-			got := Client{client}.execQuery(tt.args.ctx, tt.args.variables)
+			got, _ := Client{client}.execQuery(tt.args.ctx, tt.args.variables)
 			if len(got.User.ContributionsCollection.ContributionCalendar.Weeks) != len(tt.want.User.ContributionsCollection.ContributionCalendar.Weeks) {
 				t.Errorf("execQuery() = %v, want %v", got, tt.want)
 			}
@@ -186,7 +333,7 @@ func TestExecQueryError(t *testing.T) {
 			name:     "execQueryIsError",
 			args:     args{ctx: context.Background(), variables: map[string]interface{}{"name": githubv4.String("octocat")}},
 			queryStr: "testdata/ExecQuery/queryIsNil.json",
-			want:     "query is error. non-200 OK status code: 500 Internal Server Error body: \"Internal Server Error\"",
+			want:     "level=ERROR msg=\"query is error\" user=octocat err=\"non-200 OK status code: 500 Internal Server Error body: \\\"Internal Server Error\\\"\"",
 		},
 	}
 	for _, tt := range tests {
@@ -205,14 +352,9 @@ func TestExecQueryError(t *testing.T) {
 			t.Helper()
 
 			var buf bytes.Buffer
-			log.SetOutput(&buf)
-			defaultFlags := log.Flags()
-			log.SetFlags(0)
-			defer func() {
-				log.SetOutput(os.Stderr)
-				log.SetFlags(defaultFlags)
-				buf.Reset()
-			}()
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: withoutTime})))
+			defer slog.SetDefault(defaultLogger)
 			Client{client}.execQuery(tt.args.ctx, tt.args.variables)
 			gotPrint := strings.TrimRight(buf.String(), "\n")
 			if gotPrint != tt.want {
@@ -222,6 +364,88 @@ func TestExecQueryError(t *testing.T) {
 	}
 }
 
+func withoutTime(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		verbose bool
+		want    []string
+	}{
+		{
+			name:    "text",
+			format:  "",
+			verbose: false,
+			want:    []string{`level=INFO msg="counted commits" user=octocat streak=1`},
+		},
+		{
+			name:    "textVerbose",
+			format:  "text",
+			verbose: true,
+			want:    []string{`level=DEBUG msg="fetched window" user=octocat`, `level=INFO msg="counted commits" user=octocat streak=1`},
+		},
+		{
+			name:    "json",
+			format:  "json",
+			verbose: false,
+			want:    []string{`"level":"INFO","msg":"counted commits","user":"octocat","streak":1}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := newLogger(&buf, tt.format, tt.verbose)
+			logger.Debug("fetched window", "user", "octocat")
+			logger.Info("counted commits", "user", "octocat", "streak", 1)
+			got := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+			if len(got) != len(tt.want) {
+				t.Fatalf("newLogger() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasSuffix(got[i], tt.want[i]) {
+					t.Errorf("newLogger() = %v, want %v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewFlagSetVerbose(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "verbose", args: []string{"-verbose", "-login", "octocat"}, want: true},
+		{name: "verboseFalse", args: []string{"-verbose=false", "-login", "octocat"}, want: false},
+		{name: "notVerbose", args: []string{"-login", "octocat"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slog.SetDefault(defaultLogger)
+			fs := newFlagSet("serve")
+			login := fs.String("login", "", "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := slog.Default().Enabled(context.Background(), slog.LevelDebug); got != tt.want {
+				t.Errorf("debug enabled = %v, want %v", got, tt.want)
+			}
+			if *login != "octocat" {
+				t.Errorf("login = %v, want octocat", *login)
+			}
+		})
+	}
+}
+
 type localRoundTripper struct {
 	handler http.Handler
 }
@@ -391,12 +615,12 @@ func TestPostSlack(t *testing.T) {
 		{
 			name:   "iSOk",
 			apiRes: "testdata/slack/ok.json",
-			want:   "",
+			want:   "level=INFO msg=\"posted message\" channel=\"\" ts=1503435956.000247",
 		},
 		{
 			name:   "isError",
 			apiRes: "testdata/slack/error.json",
-			want:   "can not post message. too_many_attachments",
+			want:   "level=ERROR msg=\"can not post message\" err=too_many_attachments",
 		},
 	}
 
@@ -414,15 +638,10 @@ func TestPostSlack(t *testing.T) {
 			t.Helper()
 
 			var buf bytes.Buffer
-			log.SetOutput(&buf)
-			defaultFlags := log.Flags()
-			log.SetFlags(0)
-			defer func() {
-				log.SetOutput(os.Stderr)
-				log.SetFlags(defaultFlags)
-				buf.Reset()
-			}()
-			SlackClient{client}.postSlack("message")
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: withoutTime})))
+			defer slog.SetDefault(defaultLogger)
+			SlackClient{client}.postSlack(context.Background(), "message")
 			got := strings.TrimRight(buf.String(), "\n")
 			if got != tt.want {
 				t.Errorf("postSlack() = %v, want %v", got, tt.want)
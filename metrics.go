@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics keeps the latest results and query statistics in the Prometheus
+// text exposition format, served on /metrics or written as a node_exporter textfile.
+type Metrics struct {
+	mu               sync.Mutex
+	streak           map[string]int
+	today            map[string]int
+	total            map[string]int
+	queryDurationSum float64
+	queryCount       int
+	queryErrors      int
+	slackErrors      int
+}
+
+var metrics = newMetrics()
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func newMetrics() *Metrics {
+	return &Metrics{streak: map[string]int{}, today: map[string]int{}, total: map[string]int{}}
+}
+
+func (m *Metrics) observeResult(r *Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streak[r.userName] = r.streak
+	m.today[r.userName] = r.todayContributionCount
+	m.total[r.userName] = r.total
+}
+
+func (m *Metrics) observeQuery(duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queryDurationSum += duration.Seconds()
+	m.queryCount++
+	if err != nil {
+		m.queryErrors++
+	}
+}
+
+func (m *Metrics) observeSlack(err error) {
+	if err == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slackErrors++
+}
+
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var buf bytes.Buffer
+	writeUserGauge(&buf, "github_contribution_streak_days", "Consecutive days with at least one contribution.", m.streak)
+	writeUserGauge(&buf, "github_contributions_today", "Contributions made today.", m.today)
+	writeUserGauge(&buf, "github_contributions_total_streak", "Contributions made during the current streak.", m.total)
+	fmt.Fprintf(&buf, "# HELP github_query_duration_seconds Duration of GitHub GraphQL queries.\n# TYPE github_query_duration_seconds summary\n")
+	fmt.Fprintf(&buf, "github_query_duration_seconds_sum %g\ngithub_query_duration_seconds_count %d\n", m.queryDurationSum, m.queryCount)
+	fmt.Fprintf(&buf, "# HELP github_query_errors_total GitHub GraphQL queries that failed.\n# TYPE github_query_errors_total counter\n")
+	fmt.Fprintf(&buf, "github_query_errors_total %d\n", m.queryErrors)
+	fmt.Fprintf(&buf, "# HELP slack_post_errors_total Slack messages that could not be posted.\n# TYPE slack_post_errors_total counter\n")
+	fmt.Fprintf(&buf, "slack_post_errors_total %d\n", m.slackErrors)
+	return buf.WriteTo(w)
+}
+
+func writeUserGauge(w io.Writer, name string, help string, values map[string]int) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	users := make([]string, 0, len(values))
+	for user := range values {
+		users = append(users, user)
+	}
+	slices.Sort(users)
+	for _, user := range users {
+		fmt.Fprintf(w, "%s{user=\"%s\"} %d\n", name, labelEscaper.Replace(user), values[user])
+	}
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+func (m *Metrics) writeTextfile(path string) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := m.WriteTo(w)
+		return err
+	})
+}
+
+// writeFileAtomic replaces path with what write writes, so a reader such as
+// node_exporter or the next run never sees a half-written file.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
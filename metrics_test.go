@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const wantMetrics = `# HELP github_contribution_streak_days Consecutive days with at least one contribution.
+# TYPE github_contribution_streak_days gauge
+github_contribution_streak_days{user="hubot"} 0
+github_contribution_streak_days{user="octocat"} 3
+# HELP github_contributions_today Contributions made today.
+# TYPE github_contributions_today gauge
+github_contributions_today{user="hubot"} 0
+github_contributions_today{user="octocat"} 1
+# HELP github_contributions_total_streak Contributions made during the current streak.
+# TYPE github_contributions_total_streak gauge
+github_contributions_total_streak{user="hubot"} 0
+github_contributions_total_streak{user="octocat"} 5
+# HELP github_query_duration_seconds Duration of GitHub GraphQL queries.
+# TYPE github_query_duration_seconds summary
+github_query_duration_seconds_sum 2.5
+github_query_duration_seconds_count 2
+# HELP github_query_errors_total GitHub GraphQL queries that failed.
+# TYPE github_query_errors_total counter
+github_query_errors_total 1
+# HELP slack_post_errors_total Slack messages that could not be posted.
+# TYPE slack_post_errors_total counter
+slack_post_errors_total 1
+`
+
+func observedMetrics() *Metrics {
+	m := newMetrics()
+	m.observeResult(&Result{userName: "octocat", todayContributionCount: 1, total: 5, streak: 3})
+	m.observeResult(&Result{userName: "hubot"})
+	m.observeQuery(2*time.Second, nil)
+	m.observeQuery(500*time.Millisecond, errors.New("non-200 OK status code"))
+	m.observeSlack(nil)
+	m.observeSlack(errors.New("too_many_attachments"))
+	return m
+}
+
+func TestMetricsWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := observedMetrics().WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() err = %v", err)
+	}
+	if got := buf.String(); got != wantMetrics {
+		t.Errorf("WriteTo() = %v, want %v", got, wantMetrics)
+	}
+}
+
+func TestMetricsServeHTTP(t *testing.T) {
+	rec := httptest.NewRecorder()
+	observedMetrics().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("ServeHTTP() Content-Type = %v", got)
+	}
+	if got := rec.Body.String(); got != wantMetrics {
+		t.Errorf("ServeHTTP() = %v, want %v", got, wantMetrics)
+	}
+}
+
+func TestMetricsWriteTextfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "count_commits.prom")
+	if err := observedMetrics().writeTextfile(path); err != nil {
+		t.Fatalf("writeTextfile() err = %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != wantMetrics {
+		t.Errorf("writeTextfile() = %v, want %v", string(got), wantMetrics)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("writeTextfile() left %v files, want 1", len(entries))
+	}
+}
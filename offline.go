@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// loadCalendarFile reads a calendar saved earlier instead of asking GitHub: a
+// CSV or JSON Lines export, a calendar from the HTTP API, or a raw GraphQL
+// response like those in testdata/CountOverAYear.
+func loadCalendarFile(path string) (Query, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Query{}, err
+	}
+	defer f.Close()
+
+	var days []ContributionDay
+	switch filepath.Ext(path) {
+	case ".csv":
+		days, err = readCSVDays(f)
+	case ".jsonl":
+		days, err = readJSONLDays(f)
+	case ".json":
+		var file struct {
+			Data *Query            `json:"data"`
+			Days []ContributionDay `json:"days"`
+		}
+		if err := json.NewDecoder(f).Decode(&file); err != nil {
+			return Query{}, fmt.Errorf("can not parse %s: %w", path, err)
+		}
+		if file.Data != nil {
+			return *file.Data, nil
+		}
+		days = file.Days
+	default:
+		return Query{}, fmt.Errorf("%s must be .csv, .jsonl or .json", path)
+	}
+	if err != nil {
+		return Query{}, fmt.Errorf("can not parse %s: %w", path, err)
+	}
+	var query Query
+	query.User.ContributionsCollection.ContributionCalendar.Weeks = weeksOf(days)
+	return query, nil
+}
+
+func readCSVDays(r io.Reader) ([]ContributionDay, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no header")
+	}
+	dateColumn, countColumn, levelColumn := slices.Index(rows[0], "date"), slices.Index(rows[0], "count"), slices.Index(rows[0], "level")
+	if dateColumn < 0 || countColumn < 0 {
+		return nil, errors.New("date and count columns are required")
+	}
+	var days []ContributionDay
+	for i, row := range rows[1:] {
+		count, err := strconv.Atoi(row[countColumn])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		day := ContributionDay{Date: row[dateColumn], ContributionCount: count}
+		if levelColumn >= 0 {
+			level, err := strconv.Atoi(row[levelColumn])
+			if err != nil || level < 0 || level >= len(contributionLevels) {
+				return nil, fmt.Errorf("line %d: level must be 0 to 4", i+2)
+			}
+			day.ContributionLevel = contributionLevels[level]
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func readJSONLDays(r io.Reader) ([]ContributionDay, error) {
+	var days []ContributionDay
+	decoder := json.NewDecoder(r)
+	for {
+		var record ExportRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			return days, nil
+		} else if err != nil {
+			return nil, err
+		}
+		day := ContributionDay{Date: record.Date, ContributionCount: record.ContributionCount}
+		if record.Level != nil {
+			if *record.Level < 0 || *record.Level >= len(contributionLevels) {
+				return nil, fmt.Errorf("%s: level must be 0 to 4", record.Date)
+			}
+			day.ContributionLevel = contributionLevels[*record.Level]
+		}
+		days = append(days, day)
+	}
+}
+
+// days returns each day of the calendar once and in order, the last one winning where weeks overlap.
+func (q Query) days() []ContributionDay {
+	merged := map[string]ContributionDay{}
+	for _, week := range q.User.ContributionsCollection.ContributionCalendar.Weeks {
+		for _, day := range week.ContributionDays {
+			merged[day.Date] = day
+		}
+	}
+	return sortedDays(merged)
+}
+
+var errCalendarTooShort = errors.New("the streak goes on before the first day of the calendar")
+
+// offlineStreak counts the streak of a saved calendar the same way as a live one, up to today.
+// When the calendar starts inside the streak, the result only counts as far as the calendar
+// goes and errCalendarTooShort is returned along with it.
+func offlineStreak(login string, query Query, today time.Time) (Result, error) {
+	result := newResult(login, today)
+	if err := result.countCommittedDays(query); err != nil {
+		return result, err
+	}
+	if result.isContinue {
+		return result, fmt.Errorf("%w: %s", errCalendarTooShort, result.latestDay.Format("2006-01-02"))
+	}
+	return result, nil
+}
+
+// lastDate is the default today of a saved calendar, so it reads as it did when it was saved.
+func lastDate(days []ContributionDay) (time.Time, error) {
+	if len(days) == 0 {
+		return time.Time{}, errors.New("the calendar has no days")
+	}
+	return time.Parse("2006-01-02", days[len(days)-1].Date)
+}
+
+// filterDays keeps the days from fromValue to toValue, either of which may be empty to leave that end open.
+func filterDays(days []ContributionDay, fromValue string, toValue string) []ContributionDay {
+	filtered := []ContributionDay{}
+	for _, day := range days {
+		if (fromValue == "" || day.Date >= fromValue) && (toValue == "" || day.Date <= toValue) {
+			filtered = append(filtered, day)
+		}
+	}
+	return filtered
+}
+
+// calendarDays reads the days of a range from input when it is given, otherwise from GitHub.
+func (a *App) calendarDays(ctx context.Context, login string, input string, fromValue string, toValue string) ([]ContributionDay, error) {
+	from, to, err := parseRange(fromValue, toValue, a.now())
+	if err != nil {
+		return nil, err
+	}
+	if input == "" {
+		return a.fetchCalendar(ctx, login, from, to)
+	}
+	query, err := loadCalendarFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// a saved calendar is used whole unless the range is asked for
+	return filterDays(query.days(), fromValue, toValue), nil
+}
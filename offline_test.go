@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCalendarFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"export.csv":    "date,count,weekday\n2023-01-02,2,Monday\n2023-01-03,0,Tuesday\n",
+		"reordered.csv": "weekday,count,date\nMonday,2,2023-01-02\nTuesday,0,2023-01-03\n",
+		"noCount.csv":   "date\n2023-01-02\n",
+		"invalid.csv":   "date,count\n2023-01-02,two\n",
+		"export.jsonl":  `{"date":"2023-01-02","contributionCount":2}` + "\n" + `{"date":"2023-01-03","contributionCount":0}` + "\n",
+		"calendar.json": `{"login":"octocat","from":"2023-01-02","to":"2023-01-03","days":[{"contributionCount":2,"date":"2023-01-02"},{"contributionCount":0,"date":"2023-01-03"}]}`,
+		"calendar.txt":  "",
+		"level.csv":     "date,count,level\n2023-01-02,2,4\n",
+		"badLevel.csv":  "date,count,level\n2023-01-02,2,5\n",
+		"level.jsonl":   `{"date":"2023-01-02","contributionCount":2,"level":4}` + "\n",
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	want := []ContributionDay{{Date: "2023-01-02", ContributionCount: 2, Weekday: 1}, {Date: "2023-01-03", ContributionCount: 0, Weekday: 2}}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []ContributionDay
+		wantErr string
+	}{
+		{name: "csv", path: filepath.Join(dir, "export.csv"), want: want},
+		{name: "reorderedCSV", path: filepath.Join(dir, "reordered.csv"), want: want},
+		{name: "jsonl", path: filepath.Join(dir, "export.jsonl"), want: want},
+		{name: "apiCalendar", path: filepath.Join(dir, "calendar.json"), want: want},
+		{name: "graphQLResponse", path: "testdata/ExecQuery/totalContributionsIsOne.json", want: []ContributionDay{{Date: "2023-01-01", ContributionCount: 1}}},
+		{name: "levelCSV", path: filepath.Join(dir, "level.csv"), want: []ContributionDay{{Date: "2023-01-02", ContributionCount: 2, ContributionLevel: "FOURTH_QUARTILE", Weekday: 1}}},
+		{name: "levelJSONL", path: filepath.Join(dir, "level.jsonl"), want: []ContributionDay{{Date: "2023-01-02", ContributionCount: 2, ContributionLevel: "FOURTH_QUARTILE", Weekday: 1}}},
+		{name: "badLevel", path: filepath.Join(dir, "badLevel.csv"), wantErr: "line 2: level must be 0 to 4"},
+		{name: "noCount", path: filepath.Join(dir, "noCount.csv"), wantErr: "date and count columns are required"},
+		{name: "invalidCount", path: filepath.Join(dir, "invalid.csv"), wantErr: `line 2: strconv.Atoi: parsing "two": invalid syntax`},
+		{name: "unknownExtension", path: filepath.Join(dir, "calendar.txt"), wantErr: "must be .csv, .jsonl or .json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := loadCalendarFile(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), tt.wantErr) {
+					t.Errorf("loadCalendarFile() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCalendarFile() err = %v", err)
+			}
+			if got := query.days(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadCalendarFile() days = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfflineStreak(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		today   string
+		want    Result
+		wantErr error
+	}{
+		{
+			name: "todayIsOne",
+			path: "testdata/CountOverAYear/todayIsOne.json",
+			want: Result{userName: "octocat", todayContributionCount: 1, today: date("2023-01-03"), latestDay: date("2023-01-03"), total: 1, streak: 1, isContinue: false},
+		},
+		{
+			name: "todayAndYesterdayAreZero",
+			path: "testdata/CountOverAYear/todayAndYesterdayAreZero.json",
+			want: Result{userName: "octocat", todayContributionCount: 0, today: date("2023-01-03"), latestDay: date("2023-01-03"), total: 0, streak: 0, isContinue: false},
+		},
+		{
+			// the streak continues past the start of the file
+			name:    "earlierToday",
+			path:    "testdata/CountOverAYear/allOne.json",
+			today:   "2022-01-05",
+			want:    Result{userName: "octocat", todayContributionCount: 1, today: date("2022-01-05"), latestDay: date("2022-01-04"), total: 2, streak: 2, isContinue: true},
+			wantErr: errCalendarTooShort,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := loadCalendarFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			today, _ := lastDate(query.days())
+			if tt.today != "" {
+				today = date(tt.today)
+			}
+			got, err := offlineStreak("octocat", query, today)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("offlineStreak() = %+v %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeeksOf(t *testing.T) {
+	days := []ContributionDay{{Date: "2022-12-30"}, {Date: "2022-12-31"}, {Date: "2023-01-01"}, {Date: "2023-01-09"}}
+	want := []Week{
+		{ContributionDays: []ContributionDay{{Date: "2022-12-30"}, {Date: "2022-12-31"}}},
+		{ContributionDays: []ContributionDay{{Date: "2023-01-01"}}},
+		{ContributionDays: []ContributionDay{{Date: "2023-01-09"}}},
+	}
+	if got := weeksOf(days); !reflect.DeepEqual(got, want) {
+		t.Errorf("weeksOf() = %v, want %v", got, want)
+	}
+}
+
+func TestOfflineCommands(t *testing.T) {
+	app := &App{now: func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }}
+	input := "testdata/CountOverAYear/todayIsOne.json"
+
+	tests := []struct {
+		name string
+		run  func(out *os.File) error
+		want string
+	}{
+		{
+			name: "streak",
+			run: func(out *os.File) error {
+				return app.streakCommand(context.Background(), []string{"-login", "octocat", "-input", input, "-calendar", "-color", "none"}, out)
+			},
+			want: "octocatの今日のコミット数は1\n連続コミット日数は1\n",
+		},
+		{
+			// counted as far as the calendar goes with a warning
+			name: "streakBeforeCalendar",
+			run: func(out *os.File) error {
+				return app.streakCommand(context.Background(), []string{"-login", "octocat", "-input", "testdata/CountOverAYear/allOne.json", "-today", "2022-01-05"}, out)
+			},
+			want: "octocatの今日のコミット数は1\n連続コミット日数は2\n",
+		},
+		{
+			name: "stats",
+			run: func(out *os.File) error {
+				return app.stats(context.Background(), []string{"-login", "octocat", "-input", input, "-from", "2023-01-02"}, out)
+			},
+			want: "octocatの2023-01-02 ~ 2023-01-03の統計\n合計コミット数は1\n",
+		},
+		{
+			name: "export",
+			run: func(out *os.File) error {
+				return app.export(context.Background(), []string{"-login", "octocat", "-input", input, "-from", "2023-01-02", "-output", out.Name()})
+			},
+			want: "date,count\n2023-01-02,0\n2023-01-03,1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer out.Close()
+			if err := tt.run(out); err != nil {
+				t.Fatalf("%s err = %v", tt.name, err)
+			}
+			got, _ := os.ReadFile(out.Name())
+			if !bytes.HasPrefix(got, []byte(tt.want)) {
+				t.Errorf("%s = %q, want prefix %q", tt.name, string(got), tt.want)
+			}
+		})
+	}
+}
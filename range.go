@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxRangeYears bounds the ranges that can be asked for, each year being another query.
+const maxRangeYears = 10
+
+// parseRange reads a range of YYYY-MM-DD dates, defaulting to the year up to
+// today, the same window GitHub's profile shows.
+func parseRange(fromValue string, toValue string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toValue != "" {
+		var err error
+		if to, err = time.Parse("2006-01-02", toValue); err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+		}
+	}
+	from := to.AddDate(0, 0, -365)
+	if fromValue != "" {
+		var err error
+		if from, err = time.Parse("2006-01-02", fromValue); err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+		}
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if from.Before(to.AddDate(-maxRangeYears, 0, 0)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("range must be within %d years", maxRangeYears)
+	}
+	return from, to, nil
+}
+
+// rangeWindows splits [from, to] into consecutive periods that each fit in one
+// contributionsCollection, which GitHub limits to a year; like countOverAYear
+// a window spans 365 days, so the default calendar is still a single query.
+func rangeWindows(from time.Time, to time.Time) []Period {
+	var windows []Period
+	for start := from; !start.After(to); {
+		end := start.AddDate(0, 0, 365)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, Period{From: start, To: end})
+		start = end.AddDate(0, 0, 1)
+	}
+	return windows
+}
+
+// fetchCalendar returns each day of [from, to] once and in order, querying a year at a time.
+func (client Client) fetchCalendar(ctx context.Context, login string, from time.Time, to time.Time) ([]ContributionDay, error) {
+	merged := map[string]ContributionDay{}
+	for _, window := range rangeWindows(from, to) {
+		weeks, err := client.fetchWeeks(ctx, login, window.From, window.To)
+		if err != nil {
+			return nil, err
+		}
+		first, last := window.From.Format("2006-01-02"), window.To.Format("2006-01-02")
+		for _, week := range weeks {
+			for _, day := range week.ContributionDays {
+				// drop the padding of the calendar, which would overlap the next window
+				if day.Date >= first && day.Date <= last {
+					merged[day.Date] = day
+				}
+			}
+		}
+	}
+	return sortedDays(merged), nil
+}
+
+// sortedDays orders merged by date, filling in the weekday GitHub sends for days read from elsewhere.
+func sortedDays(merged map[string]ContributionDay) []ContributionDay {
+	days := make([]ContributionDay, 0, len(merged))
+	for _, day := range merged {
+		if d, err := time.Parse("2006-01-02", day.Date); err == nil {
+			day.Weekday = int(d.Weekday())
+		}
+		days = append(days, day)
+	}
+	slices.SortFunc(days, func(a ContributionDay, b ContributionDay) int { return strings.Compare(a.Date, b.Date) })
+	return days
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func TestRangeWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want []Period
+	}{
+		{name: "day", from: date("2023-01-01"), to: date("2023-01-01"), want: []Period{{date("2023-01-01"), date("2023-01-01")}}},
+		{name: "year", from: date("2022-01-03"), to: date("2023-01-03"), want: []Period{{date("2022-01-03"), date("2023-01-03")}}},
+		{
+			name: "years",
+			from: date("2020-01-01"),
+			to:   date("2022-06-30"),
+			want: []Period{{date("2020-01-01"), date("2020-12-31")}, {date("2021-01-01"), date("2022-01-01")}, {date("2022-01-02"), date("2022-06-30")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rangeWindows(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rangeWindows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	now := time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  string
+	}{
+		{name: "default", wantFrom: date("2022-01-03"), wantTo: date("2023-01-03")},
+		{name: "to", to: "2022-12-31", wantFrom: date("2021-12-31"), wantTo: date("2022-12-31")},
+		{name: "years", from: "2013-01-03", to: "2023-01-03", wantFrom: date("2013-01-03"), wantTo: date("2023-01-03")},
+		{name: "invalidTo", to: "2022/12/31", wantErr: "to must be YYYY-MM-DD"},
+		{name: "reversed", from: "2023-01-04", to: "2023-01-03", wantErr: "from must not be after to"},
+		{name: "overLimit", from: "2013-01-02", to: "2023-01-03", wantErr: "range must be within 10 years"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parseRange(tt.from, tt.to, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseRange() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("parseRange() = %v %v %v, want %v %v", from, to, err, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestFetchCalendar(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var body struct {
+			Variables struct {
+				From time.Time `json:"from"`
+				To   time.Time `json:"to"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		// every window answers with a padded week around its first day
+		var days []string
+		for d := body.Variables.From.AddDate(0, 0, -1); d.Before(body.Variables.From.AddDate(0, 0, 2)); d = d.AddDate(0, 0, 1) {
+			days = append(days, fmt.Sprintf(`{"contributionCount": %d, "date": "%s"}`, d.Day(), d.Format("2006-01-02")))
+		}
+		fmt.Fprintf(w, `{"data": {"user": {"contributionsCollection": {"contributionCalendar": {"weeks": [{"contributionDays": [%s]}]}}}}}`, strings.Join(days, ", "))
+	})
+	client := Client{githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})}
+
+	got, err := client.fetchCalendar(context.Background(), "octocat", date("2021-01-01"), date("2022-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ContributionDay{
+		{ContributionCount: 1, Date: "2021-01-01", Weekday: 5},
+		{ContributionCount: 2, Date: "2021-01-02", Weekday: 6},
+		{ContributionCount: 2, Date: "2022-01-02", Weekday: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fetchCalendar() = %v, want %v", got, want)
+	}
+}
+
+func TestFetchCalendarLevels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if !strings.Contains(string(body), "contributionLevel") || !strings.Contains(string(body), "color") || !strings.Contains(string(body), "weekday") {
+			t.Errorf("query = %s, want contributionLevel, color and weekday", body)
+		}
+		w.Write([]byte(`{"data": {"user": {"contributionsCollection": {"contributionCalendar": {"weeks": [{"contributionDays": [{"contributionCount": 3, "date": "2023-01-01", "contributionLevel": "SECOND_QUARTILE", "color": "#40c463", "weekday": 0}]}]}}}}}`))
+	})
+	client := Client{githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})}
+
+	got, err := client.fetchCalendar(context.Background(), "octocat", date("2023-01-01"), date("2023-01-01"))
+	want := []ContributionDay{{ContributionCount: 3, Date: "2023-01-01", ContributionLevel: "SECOND_QUARTILE", Color: "#40c463", Weekday: 0}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("fetchCalendar() = %v %v, want %v", got, err, want)
+	}
+}
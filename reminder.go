@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// reminderLevels escalate from a quiet note to notifying the whole channel.
+var reminderLevels = []struct {
+	mention string
+	text    string
+}{
+	{mention: "", text: "今日はまだコミットしていません"},
+	{mention: "<!here> ", text: "今日はまだコミットしていません！"},
+	{mention: "<!channel> ", text: ":rotating_light: 今日はまだコミットしていません！このままだと連続コミットが途切れます！"},
+}
+
+// reminderJobs turns times like "21:00" into daily jobs, the earliest at level 0.
+func reminderJobs(times []string) ([]Job, error) {
+	var clocks []time.Time
+	for _, value := range times {
+		clock, err := time.Parse("15:04", value)
+		if err != nil {
+			return nil, fmt.Errorf("reminder %q must be HH:MM", value)
+		}
+		clocks = append(clocks, clock)
+	}
+	slices.SortFunc(clocks, func(a time.Time, b time.Time) int { return a.Compare(b) })
+	jobs := make([]Job, len(clocks))
+	for i, clock := range clocks {
+		schedule, err := parseSchedule(fmt.Sprintf("%d %d * * *", clock.Minute(), clock.Hour()))
+		if err != nil {
+			return nil, err
+		}
+		jobs[i] = Job{schedule: schedule, reminder: true, level: i}
+	}
+	return jobs, nil
+}
+
+// remind posts a reminder of level unless something is committed today or the reminders are snoozed.
+func (a *App) remind(ctx context.Context, userName string, now time.Time, level int) {
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	result, err := a.streak(ctx, userName, now)
+	if err != nil {
+		return
+	}
+	if result.todayContributionCount != 0 {
+		slog.Info("reminder is not needed", "user", userName, "level", level)
+		return
+	}
+	if a.state.isSnoozed(userName, result.today) {
+		slog.Info("reminder is snoozed", "user", userName, "level", level)
+		return
+	}
+	message := result.createReminderMessage(now, level)
+	a.postReport(ctx, &result, message, a.messageOptions(&result, message))
+}
+
+func (r *Result) createReminderMessage(now time.Time, level int) string {
+	reminder := reminderLevels[min(level, len(reminderLevels)-1)]
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	left := midnight.Sub(now).Truncate(time.Minute)
+	return fmt.Sprintf("%s%s\n今日は残り%d時間%d分です\n連続コミット日数は%d\nhttps://github.com/%s", reminder.mention, reminder.text, int(left.Hours()), int(left.Minutes())%60, r.streak, r.userName)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func TestReminderJobs(t *testing.T) {
+	jobs, err := reminderJobs([]string{"23:00", "18:00", "21:30"})
+	if err != nil {
+		t.Fatalf("reminderJobs() err = %v", err)
+	}
+	from := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	want := []time.Time{
+		time.Date(2023, 1, 3, 18, 0, 0, 0, time.UTC),
+		time.Date(2023, 1, 3, 21, 30, 0, 0, time.UTC),
+		time.Date(2023, 1, 3, 23, 0, 0, 0, time.UTC),
+	}
+	for i, job := range jobs {
+		if !job.reminder || job.level != i {
+			t.Errorf("reminderJobs()[%d] reminder = %v level = %v, want level %v", i, job.reminder, job.level, i)
+		}
+		if got := job.schedule.next(from); !got.Equal(want[i]) {
+			t.Errorf("reminderJobs()[%d] next = %v, want %v", i, got, want[i])
+		}
+	}
+
+	if _, err := reminderJobs([]string{"9pm"}); err == nil || err.Error() != `reminder "9pm" must be HH:MM` {
+		t.Errorf("reminderJobs() err = %v", err)
+	}
+}
+
+func TestCreateReminderMessage(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	r := &Result{userName: "octocat", streak: 10}
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		level int
+		want  string
+	}{
+		{
+			name:  "first",
+			now:   time.Date(2023, 1, 3, 18, 0, 0, 0, tokyo),
+			level: 0,
+			want:  "今日はまだコミットしていません\n今日は残り6時間0分です\n連続コミット日数は10\nhttps://github.com/octocat",
+		},
+		{
+			name:  "second",
+			now:   time.Date(2023, 1, 3, 21, 30, 0, 0, tokyo),
+			level: 1,
+			want:  "<!here> 今日はまだコミットしていません！\n今日は残り2時間30分です\n連続コミット日数は10\nhttps://github.com/octocat",
+		},
+		{
+			name:  "beyondLastLevel",
+			now:   time.Date(2023, 1, 3, 23, 45, 0, 0, tokyo),
+			level: 5,
+			want:  "<!channel> :rotating_light: 今日はまだコミットしていません！このままだと連続コミットが途切れます！\n今日は残り0時間15分です\n連続コミット日数は10\nhttps://github.com/octocat",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.createReminderMessage(tt.now, tt.level); got != tt.want {
+				t.Errorf("createReminderMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemind(t *testing.T) {
+	todayAndYesterdayAreZeroJson, _ := testData.ReadFile("testdata/CountOverAYear/todayAndYesterdayAreZero.json")
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	okJson, _ := testData.ReadFile("testdata/slack/ok.json")
+	now := time.Date(2023, 1, 3, 21, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   []byte
+		snoozed bool
+		want    []string
+	}{
+		{name: "notCommitted", query: todayAndYesterdayAreZeroJson, want: []string{"<!here> 今日はまだコミットしていません！\n今日は残り3時間0分です\n連続コミット日数は0\nhttps://github.com/octocat"}},
+		{name: "committed", query: todayIsOneJson, want: nil},
+		{name: "snoozed", query: todayAndYesterdayAreZeroJson, snoozed: true, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+				c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+					req.ParseForm()
+					got = append(got, req.PostForm.Get("text"))
+					w.Write(okJson)
+				})
+			})
+			ts.Start()
+			defer ts.Stop()
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+				w.Write(tt.query)
+			})
+			state, _ := loadState("")
+			if tt.snoozed {
+				state.snooze("octocat", time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC))
+			}
+			app := &App{
+				graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+				slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+				strategy:      "sequential",
+				state:         state,
+			}
+			app.remind(context.Background(), "octocat", now, 1)
+			if len(got) != len(tt.want) {
+				t.Fatalf("remind() posted %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("remind() posted %v, want %v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
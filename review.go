@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// ContributionTypes counts the contributions of a collection by kind; private ones are only counted as restricted.
+type ContributionTypes struct {
+	TotalCommitContributions            int
+	TotalIssueContributions             int
+	TotalPullRequestContributions       int
+	TotalPullRequestReviewContributions int
+	TotalRepositoryContributions        int
+	RestrictedContributionsCount        int
+}
+
+type ReviewQuery struct {
+	User struct {
+		ContributionsCollection struct {
+			ContributionTypes
+			ContributionCalendar ContributionCalendar
+		} `graphql:"contributionsCollection(from: $from to: $to)"`
+	} `graphql:"user(login: $name)"`
+}
+
+type YearReview struct {
+	login         string
+	year          int
+	stats         Stats
+	longestStreak Span
+	bestMonth     Distribution
+	bestWeekday   Distribution
+	types         ContributionTypes
+}
+
+func newYearReview(login string, year int, days []ContributionDay, types ContributionTypes) YearReview {
+	review := YearReview{login: login, year: year, stats: computeStats(login, days), types: types}
+	review.longestStreak = longestSpan(days, func(count int) bool { return count > 0 })
+	for _, month := range review.stats.Months {
+		if month.Total > review.bestMonth.Total {
+			review.bestMonth = month
+		}
+	}
+	for _, weekday := range review.stats.Weekdays {
+		if weekday.Total > review.bestWeekday.Total {
+			review.bestWeekday = weekday
+		}
+	}
+	return review
+}
+
+func (r *YearReview) typeCounts() []struct {
+	name  string
+	count int
+} {
+	return []struct {
+		name  string
+		count int
+	}{
+		{name: "コミット", count: r.types.TotalCommitContributions},
+		{name: "Issue", count: r.types.TotalIssueContributions},
+		{name: "Pull Request", count: r.types.TotalPullRequestContributions},
+		{name: "レビュー", count: r.types.TotalPullRequestReviewContributions},
+		{name: "リポジトリ作成", count: r.types.TotalRepositoryContributions},
+		{name: "非公開", count: r.types.RestrictedContributionsCount},
+	}
+}
+
+func (r *YearReview) summary() []string {
+	lines := []string{
+		fmt.Sprintf("合計コントリビューション数は%d", r.stats.Total),
+		fmt.Sprintf("コミットした日は%d/%d日(%.1f%%)", r.stats.ActiveDays, r.stats.Days, r.stats.ActivePercentage),
+	}
+	if r.longestStreak.Days > 0 {
+		lines = append(lines, fmt.Sprintf("最長連続コミット日数は%d(%s ~ %s)", r.longestStreak.Days, r.longestStreak.From, r.longestStreak.To))
+	}
+	if r.bestMonth.Total > 0 {
+		lines = append(lines, fmt.Sprintf("最もコミットした月は%s(%d)", r.bestMonth.Name, r.bestMonth.Total))
+		lines = append(lines, fmt.Sprintf("最もコミットした曜日は%s(%d)", r.bestWeekday.Name, r.bestWeekday.Total))
+	}
+	return lines
+}
+
+func (r *YearReview) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %sの%d年のふりかえり\n\n", r.login, r.year)
+	for _, line := range r.summary() {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+	b.WriteString("\n## 種類別\n\n| 種類 | 数 |\n| --- | ---: |\n")
+	for _, t := range r.typeCounts() {
+		fmt.Fprintf(&b, "| %s | %d |\n", t.name, t.count)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r *YearReview) createMessage() string {
+	var types []string
+	for _, t := range r.typeCounts() {
+		types = append(types, fmt.Sprintf("%s %d", t.name, t.count))
+	}
+	return fmt.Sprintf("*%sの%d年のふりかえり*\n%s\n種類別は%s\nhttps://github.com/%s", r.login, r.year, strings.Join(r.summary(), "\n"), strings.Join(types, " / "), r.login)
+}
+
+// yearReview fetches year up to today, so a review of the current year covers it so far.
+func (a *App) yearReview(ctx context.Context, login string, year int, now time.Time) (YearReview, error) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC); today.Before(to) {
+		to = today
+	}
+	if to.Before(from) {
+		return YearReview{}, fmt.Errorf("%d has not started yet", year)
+	}
+	var query ReviewQuery
+	variables := map[string]interface{}{
+		"name": githubv4.String(login),
+		"from": githubv4.DateTime{Time: from},
+		// the contribution types are counted by time, so the last day ends at the next midnight
+		"to": githubv4.DateTime{Time: to.AddDate(0, 0, 1)},
+	}
+	if err := a.graphqlClient.Query(ctx, &query, variables); err != nil {
+		return YearReview{}, err
+	}
+	collection := query.User.ContributionsCollection
+	var days []ContributionDay
+	for _, week := range collection.ContributionCalendar.Weeks {
+		for _, day := range week.ContributionDays {
+			// the calendar is padded to whole weeks
+			if strings.HasPrefix(day.Date, fmt.Sprint(year)) {
+				days = append(days, day)
+			}
+		}
+	}
+	return newYearReview(login, year, days, collection.ContributionTypes), nil
+}
+
+// review writes the year in review as Markdown, or posts it to Slack with -format slack.
+func (a *App) review(ctx context.Context, args []string) error {
+	now := a.now()
+	fs := newFlagSet("review")
+	login := fs.String("login", os.Getenv("GH_USER_NAME"), "GitHub login to review")
+	year := fs.Int("year", now.Year()-1, "year to review")
+	format := fs.String("format", "markdown", "output format: markdown or slack")
+	output := fs.String("output", "-", "path to write the Markdown to, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("-login is required")
+	}
+	if *format != "markdown" && *format != "slack" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	review, err := a.yearReview(ctx, *login, *year, now)
+	if err != nil {
+		return err
+	}
+	if *format == "slack" {
+		a.slackClient.postSlackTo(ctx, a.channel(ctx, *login), review.createMessage())
+		return nil
+	}
+	return writeFile(*output, review.writeMarkdown)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+var reviewDays = []ContributionDay{
+	{Date: "2023-01-01", ContributionCount: 1},
+	{Date: "2023-01-02", ContributionCount: 2},
+	{Date: "2023-01-03", ContributionCount: 0},
+	{Date: "2023-02-01", ContributionCount: 3},
+	{Date: "2023-02-02", ContributionCount: 4},
+	{Date: "2023-02-03", ContributionCount: 5},
+}
+
+var reviewTypes = ContributionTypes{TotalCommitContributions: 10, TotalIssueContributions: 2, TotalPullRequestContributions: 1, TotalPullRequestReviewContributions: 1, TotalRepositoryContributions: 1}
+
+func TestYearReviewCreateMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		days []ContributionDay
+		want string
+	}{
+		{
+			name: "year",
+			days: reviewDays,
+			want: "*octocatの2023年のふりかえり*\n合計コントリビューション数は15\nコミットした日は5/6日(83.3%)\n最長連続コミット日数は3(2023-02-01 ~ 2023-02-03)\n最もコミットした月は2023-02(12)\n最もコミットした曜日はFriday(5)\n種類別はコミット 10 / Issue 2 / Pull Request 1 / レビュー 1 / リポジトリ作成 1 / 非公開 0\nhttps://github.com/octocat",
+		},
+		{
+			name: "empty",
+			days: nil,
+			want: "*octocatの2023年のふりかえり*\n合計コントリビューション数は0\nコミットした日は0/0日(0.0%)\n種類別はコミット 10 / Issue 2 / Pull Request 1 / レビュー 1 / リポジトリ作成 1 / 非公開 0\nhttps://github.com/octocat",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review := newYearReview("octocat", 2023, tt.days, reviewTypes)
+			if got := review.createMessage(); got != tt.want {
+				t.Errorf("createMessage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYearReviewWriteMarkdown(t *testing.T) {
+	review := newYearReview("octocat", 2023, reviewDays, reviewTypes)
+	var b bytes.Buffer
+	if err := review.writeMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	// 2023-02-01 to 2023-02-03 are Wednesday to Friday, so Friday is the busiest weekday
+	want := "# octocatの2023年のふりかえり\n\n" +
+		"- 合計コントリビューション数は15\n" +
+		"- コミットした日は5/6日(83.3%)\n" +
+		"- 最長連続コミット日数は3(2023-02-01 ~ 2023-02-03)\n" +
+		"- 最もコミットした月は2023-02(12)\n" +
+		"- 最もコミットした曜日はFriday(5)\n" +
+		"\n## 種類別\n\n| 種類 | 数 |\n| --- | ---: |\n" +
+		"| コミット | 10 |\n| Issue | 2 |\n| Pull Request | 1 |\n| レビュー | 1 |\n| リポジトリ作成 | 1 |\n| 非公開 | 0 |\n"
+	if b.String() != want {
+		t.Errorf("writeMarkdown() = %q, want %q", b.String(), want)
+	}
+}
+
+func TestYearReviewRange(t *testing.T) {
+	var got struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := struct {
+			Variables any `json:"variables"`
+		}{Variables: &got}
+		json.NewDecoder(req.Body).Decode(&body)
+		w.Write([]byte(`{"data": {"user": {"contributionsCollection": {"contributionCalendar": {"weeks": []}}}}}`))
+	})
+	app := &App{graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}})}
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		year   int
+		wantTo time.Time
+	}{
+		{name: "pastYear", year: 2022, wantTo: date("2023-01-01")},
+		{name: "currentYear", year: 2023, wantTo: date("2023-03-02")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := app.yearReview(context.Background(), "octocat", tt.year, now); err != nil {
+				t.Fatalf("yearReview() err = %v", err)
+			}
+			if !got.From.Equal(time.Date(tt.year, time.January, 1, 0, 0, 0, 0, time.UTC)) || !got.To.Equal(tt.wantTo) {
+				t.Errorf("yearReview() queried %v ~ %v, want to %v", got.From, got.To, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestReview(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		// the calendar is padded with the last days of 2022
+		w.Write([]byte(`{"data": {"user": {"contributionsCollection": {"totalCommitContributions": 3, "totalIssueContributions": 0, "totalPullRequestContributions": 1, "totalPullRequestReviewContributions": 0, "totalRepositoryContributions": 0, "restrictedContributionsCount": 0, "contributionCalendar": {"weeks": [{"contributionDays": [{"contributionCount": 9, "date": "2022-12-31"}, {"contributionCount": 4, "date": "2023-01-01"}]}]}}}}}`))
+	})
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		now:           func() time.Time { return time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC) },
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		want    string
+	}{
+		{name: "currentYear", args: []string{"-login", "octocat", "-year", "2023", "-output", filepath.Join(dir, "review.md")}, want: "- 合計コントリビューション数は4\n- コミットした日は1/1日(100.0%)\n"},
+		{name: "notStarted", args: []string{"-login", "octocat", "-year", "2024"}, wantErr: "2024 has not started yet"},
+		{name: "noLogin", args: []string{"-login", ""}, wantErr: "-login is required"},
+		{name: "unknownFormat", args: []string{"-login", "octocat", "-format", "html"}, wantErr: `unknown format "html"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := app.review(context.Background(), tt.args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("review() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("review() err = %v", err)
+			}
+			got, _ := os.ReadFile(filepath.Join(dir, "review.md"))
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("review() = %v, want to contain %v", string(got), tt.want)
+			}
+		})
+	}
+}
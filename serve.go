@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultSchedule = "37 11 * * *"
+
+var errNoSchedules = errors.New("no schedules are configured")
+
+type Config struct {
+	Users []UserConfig `json:"users"`
+}
+
+type UserConfig struct {
+	Login string `json:"login"`
+	Recipient
+	// Timezone is an IANA name such as Asia/Tokyo; schedules and "today" are evaluated in it
+	Timezone  string   `json:"timezone"`
+	Schedules []string `json:"schedules"`
+	// Reminders are local times like "21:00" to remind again while nothing is committed, each later one more urgent
+	Reminders []string       `json:"reminders"`
+	Digests   []DigestConfig `json:"digests"`
+	Goals     []Goal         `json:"goals"`
+}
+
+// DigestConfig posts a weekly or monthly digest on schedule, e.g. "0 9 * * 1" for Monday mornings.
+type DigestConfig struct {
+	Period   string `json:"period"`
+	Schedule string `json:"schedule"`
+}
+
+type Job struct {
+	login    string
+	location *time.Location
+	schedule Schedule
+	// reminder jobs only post while nothing is committed today, escalating with level
+	reminder bool
+	level    int
+	// digest jobs post a summary of this period instead of the daily report
+	digest string
+}
+
+type Scheduler struct {
+	jobs  []Job
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// loadConfig reads the JSON config at path. Without a path the single user of
+// GH_USER_NAME is checked on SCHEDULE, or on the time run.yml uses, in UTC,
+// reported to SLACK_USER_ID or SLACK_USER_EMAIL and held to GOAL_DAILY and
+// GOAL_WEEKLY like a one-shot run.
+func loadConfig(path string) (Config, error) {
+	if path == "" {
+		if os.Getenv("GH_USER_NAME") == "" {
+			return Config{}, nil
+		}
+		schedule := os.Getenv("SCHEDULE")
+		if schedule == "" {
+			schedule = defaultSchedule
+		}
+		return Config{Users: []UserConfig{{Login: os.Getenv("GH_USER_NAME"), Recipient: envRecipient(), Schedules: []string{schedule}, Goals: envGoals()}}}, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	if err := json.Unmarshal(b, &config); err != nil {
+		return Config{}, fmt.Errorf("can not parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func (c Config) jobs() ([]Job, error) {
+	var jobs []Job
+	for _, user := range c.Users {
+		if user.Login == "" {
+			return nil, errors.New("user without login")
+		}
+		location, err := time.LoadLocation(user.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", user.Login, err)
+		}
+		for _, goal := range user.Goals {
+			if err := goal.validate(); err != nil {
+				return nil, fmt.Errorf("%s: %w", user.Login, err)
+			}
+		}
+		for _, spec := range user.Schedules {
+			schedule, err := parseSchedule(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", user.Login, err)
+			}
+			jobs = append(jobs, Job{login: user.Login, location: location, schedule: schedule})
+		}
+		reminders, err := reminderJobs(user.Reminders)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", user.Login, err)
+		}
+		for _, reminder := range reminders {
+			reminder.login, reminder.location = user.Login, location
+			jobs = append(jobs, reminder)
+		}
+		for _, digest := range user.Digests {
+			if _, _, err := digestPeriods(digest.Period, time.Time{}); err != nil {
+				return nil, fmt.Errorf("%s: %w", user.Login, err)
+			}
+			schedule, err := parseSchedule(digest.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", user.Login, err)
+			}
+			jobs = append(jobs, Job{login: user.Login, location: location, schedule: schedule, digest: digest.Period})
+		}
+	}
+	if len(jobs) == 0 {
+		return nil, errNoSchedules
+	}
+	return jobs, nil
+}
+
+func newScheduler(jobs []Job) *Scheduler {
+	return &Scheduler{jobs: jobs, now: time.Now, after: time.After}
+}
+
+// start runs every job on its schedule until ctx is done.
+func (s *Scheduler) start(ctx context.Context, run func(ctx context.Context, job Job, now time.Time)) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job, run)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job, run func(ctx context.Context, job Job, now time.Time)) {
+	var last time.Time
+	for ctx.Err() == nil {
+		from := s.now()
+		// never fire the same minute twice even if the timer was early
+		if from.Before(last) {
+			from = last
+		}
+		next := job.schedule.next(from.In(job.location))
+		if next.IsZero() {
+			slog.Error("schedule never fires", "user", job.login)
+			return
+		}
+		slog.Debug("scheduled", "user", job.login, "at", next)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.after(next.Sub(s.now())):
+			last = next
+			run(ctx, job, s.now().In(job.location))
+		}
+	}
+}
+
+func (a *App) serve(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	configPath := fs.String("config", os.Getenv("COUNT_COMMITS_CONFIG"), "path to the JSON config of users and schedules")
+	addr := fs.String("addr", os.Getenv("LISTEN_ADDR"), "address to serve the HTTP API and /metrics on, e.g. :8080")
+	ttl := fs.Duration("cache-ttl", cacheTTL(), "how long the HTTP API caches a result")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	for _, user := range config.Users {
+		a.recipients[user.Login] = user.Recipient
+		a.goals[user.Login] = user.Goals
+	}
+	jobs, err := config.jobs()
+	// the HTTP API is useful on its own, so schedules are only required without it
+	if err != nil && !(*addr != "" && errors.Is(err, errNoSchedules)) {
+		return err
+	}
+
+	if *addr != "" {
+		api := newAPI(a, *ttl)
+		for _, user := range config.Users {
+			api.logins[user.Login] = true
+		}
+		a.snoozeButton = api.signingSecret != ""
+		// bind before scheduling anything so that a bad address fails serve instead of leaving it idle
+		listener, err := net.Listen("tcp", *addr)
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Addr: *addr, Handler: api.handler()}
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("can not serve", "addr", *addr, "err", err)
+			}
+		}()
+		defer func() {
+			server.Shutdown(context.WithoutCancel(ctx))
+			// slash commands acknowledged before shutting down still get their answer
+			api.background.Wait()
+		}()
+	}
+
+	slog.Info("serving", "addr", *addr, "jobs", len(jobs))
+	newScheduler(jobs).start(ctx, func(ctx context.Context, job Job, now time.Time) {
+		if job.digest != "" {
+			a.postDigest(ctx, job.login, now, job.digest)
+			return
+		}
+		if job.reminder {
+			a.remind(ctx, job.login, now, job.level)
+			return
+		}
+		a.run(ctx, job.login, now)
+	})
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	os.WriteFile(path, []byte(`{"users": [{"login": "octocat", "timezone": "Asia/Tokyo", "schedules": ["0 18 * * *"], "reminders": ["21:00", "23:00"], "digests": [{"period": "weekly", "schedule": "0 9 * * 1"}]}, {"login": "hubot", "schedules": ["37 11 * * *"]}]}`), 0644)
+	invalidPath := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalidPath, []byte(`{"users": [`), 0644)
+	t.Setenv("GH_USER_NAME", "octocat")
+	t.Setenv("SCHEDULE", "")
+
+	tests := []struct {
+		name     string
+		path     string
+		wantJobs []string
+		wantErr  bool
+	}{
+		{name: "env", path: "", wantJobs: []string{"octocat UTC"}},
+		{name: "file", path: path, wantJobs: []string{"octocat Asia/Tokyo", "octocat Asia/Tokyo", "octocat Asia/Tokyo", "octocat Asia/Tokyo", "hubot UTC"}},
+		{name: "invalid", path: invalidPath, wantErr: true},
+		{name: "notFound", path: filepath.Join(dir, "notFound.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := loadConfig(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadConfig() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			jobs, err := config.jobs()
+			if err != nil {
+				t.Fatalf("jobs() err = %v", err)
+			}
+			if len(jobs) != len(tt.wantJobs) {
+				t.Fatalf("jobs() = %v, want %v", jobs, tt.wantJobs)
+			}
+			for i, job := range jobs {
+				if got := job.login + " " + job.location.String(); got != tt.wantJobs[i] {
+					t.Errorf("jobs()[%d] = %v, want %v", i, got, tt.wantJobs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConfigJobsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{
+			name:   "noSchedules",
+			config: Config{Users: []UserConfig{{Login: "octocat"}}},
+			want:   "no schedules are configured",
+		},
+		{
+			name:   "noLogin",
+			config: Config{Users: []UserConfig{{Schedules: []string{defaultSchedule}}}},
+			want:   "user without login",
+		},
+		{
+			name:   "unknownTimezone",
+			config: Config{Users: []UserConfig{{Login: "octocat", Timezone: "Mars/Olympus", Schedules: []string{defaultSchedule}}}},
+			want:   "octocat: unknown time zone Mars/Olympus",
+		},
+		{
+			name:   "invalidReminder",
+			config: Config{Users: []UserConfig{{Login: "octocat", Reminders: []string{"25:00"}}}},
+			want:   `octocat: reminder "25:00" must be HH:MM`,
+		},
+		{
+			name:   "invalidGoal",
+			config: Config{Users: []UserConfig{{Login: "octocat", Schedules: []string{defaultSchedule}, Goals: []Goal{{Period: goalDaily, Target: 0}}}}},
+			want:   "octocat: goal target must be positive, got 0",
+		},
+		{
+			name:   "unknownDigestPeriod",
+			config: Config{Users: []UserConfig{{Login: "octocat", Digests: []DigestConfig{{Period: "daily", Schedule: "0 9 * * *"}}}}},
+			want:   `octocat: unknown digest period "daily"`,
+		},
+		{
+			name:   "invalidSchedule",
+			config: Config{Users: []UserConfig{{Login: "octocat", Schedules: []string{"* * *"}}}},
+			want:   `octocat: schedule "* * *" must have 5 fields`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.config.jobs()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("jobs() err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeEnvConfig(t *testing.T) {
+	t.Setenv("GH_USER_NAME", "octocat")
+	t.Setenv("SLACK_USER_ID", "U123")
+	t.Setenv("SLACK_USER_EMAIL", "")
+	t.Setenv("GOAL_DAILY", "3")
+	t.Setenv("GOAL_WEEKLY", "")
+	t.Setenv("COUNT_COMMITS_CONFIG", "")
+	t.Setenv("LISTEN_ADDR", "")
+	t.Setenv("STATE_FILE", "")
+	t.Setenv("DATABASE_FILE", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	app := newApp(ctx)
+	if err := app.serve(ctx, nil); err != nil {
+		t.Fatalf("serve() err = %v", err)
+	}
+	if got := app.recipients["octocat"]; got.SlackUserID != "U123" {
+		t.Errorf("serve() recipient = %+v, want U123", got)
+	}
+	if got := app.goals["octocat"]; len(got) != 1 || got[0] != (Goal{Period: goalDaily, Target: 3}) {
+		t.Errorf("serve() goals = %+v, want a daily goal of 3", got)
+	}
+}
+
+func TestServeAddrInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	t.Setenv("GH_USER_NAME", "")
+
+	app := &App{recipients: map[string]Recipient{}, goals: map[string][]Goal{}}
+	if err := app.serve(context.Background(), []string{"-config", "", "-addr", listener.Addr().String()}); err == nil {
+		t.Errorf("serve() on an address in use err = nil, want an error")
+	}
+}
+
+func TestSchedulerLoop(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	schedule, _ := parseSchedule("0 18,23 * * *")
+	job := Job{login: "octocat", location: tokyo, schedule: schedule}
+
+	now := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	s := &Scheduler{
+		jobs: []Job{job},
+		now:  func() time.Time { return now },
+		after: func(d time.Duration) <-chan time.Time {
+			now = now.Add(d)
+			c := make(chan time.Time, 1)
+			c <- now
+			return c
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got []time.Time
+	s.start(ctx, func(_ context.Context, job Job, now time.Time) {
+		got = append(got, now)
+		if len(got) == 3 {
+			cancel()
+		}
+	})
+
+	want := []time.Time{
+		time.Date(2023, 1, 3, 18, 0, 0, 0, tokyo),
+		time.Date(2023, 1, 3, 23, 0, 0, 0, tokyo),
+		time.Date(2023, 1, 4, 18, 0, 0, 0, tokyo),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("start() ran at %v, want %v", got, want)
+	}
+	for i := range got {
+		if !got[i].Equal(want[i]) || got[i].Location() != tokyo {
+			t.Errorf("start() ran at %v, want %v", got[i], want[i])
+		}
+	}
+}
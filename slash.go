@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// verifySlackRequest checks the signature Slack puts on every request and
+// leaves the body readable for the caller.
+func (api *API) verifySlackRequest(req *http.Request) error {
+	verifier, err := slack.NewSecretsVerifier(req.Header, api.signingSecret)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(io.TeeReader(req.Body, &verifier))
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return verifier.Ensure()
+}
+
+// parseCommandText reads "/streak [login] [public]"; the answer is only visible to the caller unless public is given.
+func parseCommandText(text string, defaultLogin string) (string, string) {
+	login, responseType := defaultLogin, slack.ResponseTypeEphemeral
+	for _, field := range strings.Fields(text) {
+		if field == "public" {
+			responseType = slack.ResponseTypeInChannel
+		} else {
+			login = strings.TrimPrefix(field, "@")
+		}
+	}
+	return login, responseType
+}
+
+// slashCommand acknowledges at once because Slack gives up after 3 seconds,
+// and posts the streak to the command's response_url when it is counted.
+func (api *API) slashCommand(w http.ResponseWriter, req *http.Request) {
+	if err := api.verifySlackRequest(req); err != nil {
+		slog.Warn("can not verify slack request", "err", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	command, err := slack.SlashCommandParse(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	login, responseType := parseCommandText(command.Text, api.defaultLogin)
+	if login == "" {
+		writeJSON(w, http.StatusOK, slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: fmt.Sprintf("使い方: %s <GitHubのユーザー名> [public]", command.Command)})
+		return
+	}
+	// like the /users endpoints, only configured logins are counted
+	if !api.logins[login] {
+		writeJSON(w, http.StatusOK, slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: login + "は登録されていないユーザーです"})
+		return
+	}
+	writeJSON(w, http.StatusOK, slack.Msg{ResponseType: slack.ResponseTypeEphemeral, Text: login + "のコミットを数えています..."})
+
+	api.background.Add(1)
+	go func() {
+		defer api.background.Done()
+		ctx := context.WithoutCancel(req.Context())
+		message := &slack.WebhookMessage{ResponseType: responseType, ReplaceOriginal: true}
+		result, err := api.cachedStreak(ctx, login, api.now())
+		if err != nil {
+			message.ResponseType = slack.ResponseTypeEphemeral
+			message.Text = login + "のコミットを数えられませんでした"
+		} else {
+			message.Text = result.createCommandMessage()
+		}
+		ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+		defer cancel()
+		if err := slack.PostWebhookContext(ctx, command.ResponseURL, message); err != nil {
+			slog.Error("can not respond to slash command", "user", login, "err", err)
+		}
+	}()
+}
+
+func (r *Result) createCommandMessage() string {
+	return fmt.Sprintf("%sの今日のコミット数は%d\n連続コミット日数は%d\n合計コミット数は%d\n平均コミット数は%f\n期間は%s ~\nhttps://github.com/%s", r.userName, r.todayContributionCount, r.streak, r.total, r.average(), r.latestDay.Format("2006-01-02"), r.userName)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func signedSlackRequest(path string, secret string, body string) *http.Request {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlashCommand(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+
+	tests := []struct {
+		name         string
+		text         string
+		defaultLogin string
+		secret       string
+		status       int
+		wantStatus   int
+		wantAck      string
+		wantResponse slack.WebhookMessage
+	}{
+		{
+			name:         "login",
+			text:         "octocat",
+			secret:       "signingSecret",
+			status:       http.StatusOK,
+			wantStatus:   http.StatusOK,
+			wantAck:      "octocatのコミットを数えています...",
+			wantResponse: slack.WebhookMessage{ResponseType: slack.ResponseTypeEphemeral, ReplaceOriginal: true, Text: "octocatの今日のコミット数は1\n連続コミット日数は1\n合計コミット数は1\n平均コミット数は1.000000\n期間は2023-01-03 ~\nhttps://github.com/octocat"},
+		},
+		{
+			name:         "defaultLoginInChannel",
+			text:         "public",
+			defaultLogin: "octocat",
+			secret:       "signingSecret",
+			status:       http.StatusOK,
+			wantStatus:   http.StatusOK,
+			wantAck:      "octocatのコミットを数えています...",
+			wantResponse: slack.WebhookMessage{ResponseType: slack.ResponseTypeInChannel, ReplaceOriginal: true, Text: "octocatの今日のコミット数は1\n連続コミット日数は1\n合計コミット数は1\n平均コミット数は1.000000\n期間は2023-01-03 ~\nhttps://github.com/octocat"},
+		},
+		{
+			name:       "noLogin",
+			text:       "",
+			secret:     "signingSecret",
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+			wantAck:    "使い方: /streak <GitHubのユーザー名> [public]",
+		},
+		{
+			name:       "untrackedLogin",
+			text:       "torvalds",
+			secret:     "signingSecret",
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+			wantAck:    "torvaldsは登録されていないユーザーです",
+		},
+		{
+			name:         "githubIsError",
+			text:         "@octocat public",
+			secret:       "signingSecret",
+			status:       http.StatusInternalServerError,
+			wantStatus:   http.StatusOK,
+			wantAck:      "octocatのコミットを数えています...",
+			wantResponse: slack.WebhookMessage{ResponseType: slack.ResponseTypeEphemeral, ReplaceOriginal: true, Text: "octocatのコミットを数えられませんでした"},
+		},
+		{
+			name:       "invalidSignature",
+			text:       "octocat",
+			secret:     "wrongSecret",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got slack.WebhookMessage
+			ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+				c.Handle("/response", func(w http.ResponseWriter, req *http.Request) {
+					json.NewDecoder(req.Body).Decode(&got)
+				})
+			})
+			ts.Start()
+			defer ts.Stop()
+
+			api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {
+				if tt.status != http.StatusOK {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write(todayIsOneJson)
+			})
+			api.signingSecret = "signingSecret"
+			api.defaultLogin = tt.defaultLogin
+
+			form := url.Values{"command": {"/streak"}, "text": {tt.text}, "response_url": {ts.GetAPIURL() + "response"}}
+			rec := httptest.NewRecorder()
+			api.handler().ServeHTTP(rec, signedSlackRequest("/slack/commands", tt.secret, form.Encode()))
+			api.background.Wait()
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("slashCommand() status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var ack slack.Msg
+			json.NewDecoder(rec.Body).Decode(&ack)
+			if ack.Text != tt.wantAck || ack.ResponseType != slack.ResponseTypeEphemeral {
+				t.Errorf("slashCommand() ack = %+v, want %v", ack, tt.wantAck)
+			}
+			if got.Text != tt.wantResponse.Text || got.ResponseType != tt.wantResponse.ResponseType || got.ReplaceOriginal != tt.wantResponse.ReplaceOriginal {
+				t.Errorf("slashCommand() response = %+v, want %+v", got, tt.wantResponse)
+			}
+		})
+	}
+}
+
+func TestSlashCommandDisabled(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {})
+	api.signingSecret = ""
+	rec := httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, signedSlackRequest("/slack/commands", "", "command=%2Fstreak"))
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("slashCommand() status = %v, want not found", rec.Code)
+	}
+}
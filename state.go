@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// State is what has to survive between runs, kept as JSON in STATE_FILE.
+// Without a path it only lives as long as the process.
+type State struct {
+	mu   sync.Mutex
+	path string
+	// Snoozed maps a login to the day its no-commit reminders were stopped
+	Snoozed map[string]string `json:"snoozed"`
+	// Messages maps a login to the report later runs reply to or edit
+	Messages map[string]PostedMessage `json:"messages"`
+}
+
+type PostedMessage struct {
+	// Key is the day or streak the message reports on
+	Key     string `json:"key"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+func loadState(path string) (*State, error) {
+	state := &State{path: path, Snoozed: map[string]string{}, Messages: map[string]PostedMessage{}}
+	if path == "" {
+		return state, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(b, state); err != nil {
+		return state, err
+	}
+	if state.Snoozed == nil {
+		state.Snoozed = map[string]string{}
+	}
+	if state.Messages == nil {
+		state.Messages = map[string]PostedMessage{}
+	}
+	return state, nil
+}
+
+// save must be called with mu held.
+func (s *State) save() error {
+	if s.path == "" {
+		return nil
+	}
+	return writeFileAtomic(s.path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(s)
+	})
+}
+
+func (s *State) snooze(login string, day time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Snoozed[login] = day.Format("2006-01-02")
+	return s.save()
+}
+
+func (s *State) isSnoozed(login string, day time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Snoozed[login] == day.Format("2006-01-02")
+}
+
+// message returns the message posted for login about key, if any.
+func (s *State) message(login string, key string) (PostedMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message, ok := s.Messages[login]
+	return message, ok && message.Key == key
+}
+
+func (s *State) saveMessage(login string, message PostedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Messages[login] = message
+	return s.save()
+}
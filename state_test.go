@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateSnooze(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	today := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	state, err := loadState(path)
+	if err != nil {
+		t.Fatalf("loadState() err = %v", err)
+	}
+	if state.isSnoozed("octocat", today) {
+		t.Errorf("isSnoozed() = true before snooze")
+	}
+	if err := state.snooze("octocat", today); err != nil {
+		t.Fatalf("snooze() err = %v", err)
+	}
+
+	reloaded, err := loadState(path)
+	if err != nil {
+		t.Fatalf("loadState() err = %v", err)
+	}
+	tests := []struct {
+		name  string
+		login string
+		day   time.Time
+		want  bool
+	}{
+		{name: "snoozed", login: "octocat", day: today, want: true},
+		{name: "tomorrow", login: "octocat", day: today.AddDate(0, 0, 1), want: false},
+		{name: "otherUser", login: "hubot", day: today, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reloaded.isSnoozed(tt.login, tt.day); got != tt.want {
+				t.Errorf("isSnoozed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadStateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	os.WriteFile(path, []byte("{"), 0644)
+	state, err := loadState(path)
+	if err == nil {
+		t.Errorf("loadState() err = nil, want error")
+	}
+	if state == nil || state.isSnoozed("octocat", time.Now()) {
+		t.Errorf("loadState() = %v, want empty state", state)
+	}
+}
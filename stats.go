@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// rollingWindows are the trailing numbers of days averaged by Stats.
+var rollingWindows = []int{7, 30, 90}
+
+type Stats struct {
+	Login            string           `json:"login"`
+	From             string           `json:"from"`
+	To               string           `json:"to"`
+	Days             int              `json:"days"`
+	ActiveDays       int              `json:"activeDays"`
+	ActivePercentage float64          `json:"activePercentage"`
+	Total            int              `json:"total"`
+	RollingAverages  []RollingAverage `json:"rollingAverages"`
+	Weekdays         []Distribution   `json:"weekdays"`
+	Months           []Distribution   `json:"months"`
+	Median           float64          `json:"median"`
+	Percentiles      []Percentile     `json:"percentiles"`
+	BusiestDay       ContributionDay  `json:"busiestDay"`
+	LongestGap       Span             `json:"longestGap"`
+}
+
+type RollingAverage struct {
+	Days    int     `json:"days"`
+	Average float64 `json:"average"`
+}
+
+type Distribution struct {
+	Name       string  `json:"name"`
+	Days       int     `json:"days"`
+	ActiveDays int     `json:"activeDays"`
+	Total      int     `json:"total"`
+	Average    float64 `json:"average"`
+}
+
+type Percentile struct {
+	Percentile int `json:"percentile"`
+	Count      int `json:"count"`
+}
+
+// Span is a run of consecutive days, such as a streak or a gap.
+type Span struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	Days int    `json:"days"`
+}
+
+// computeStats summarizes days, which are ordered by date as GitHub returns them.
+func computeStats(login string, days []ContributionDay) Stats {
+	stats := Stats{Login: login, Days: len(days), RollingAverages: []RollingAverage{}, Weekdays: []Distribution{}, Months: []Distribution{}, Percentiles: []Percentile{}}
+	if len(days) == 0 {
+		return stats
+	}
+	stats.From, stats.To = days[0].Date, days[len(days)-1].Date
+
+	weekdays := make([]Distribution, 7)
+	for i := range weekdays {
+		weekdays[i].Name = time.Weekday(i).String()
+	}
+	counts := make([]int, 0, len(days))
+	for _, day := range days {
+		counts = append(counts, day.ContributionCount)
+		stats.Total += day.ContributionCount
+		if day.ContributionCount > stats.BusiestDay.ContributionCount {
+			stats.BusiestDay = day
+		}
+
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		month := date.Format("2006-01")
+		if len(stats.Months) == 0 || stats.Months[len(stats.Months)-1].Name != month {
+			stats.Months = append(stats.Months, Distribution{Name: month})
+		}
+		for _, d := range []*Distribution{&weekdays[date.Weekday()], &stats.Months[len(stats.Months)-1]} {
+			d.Days++
+			d.Total += day.ContributionCount
+			if day.ContributionCount > 0 {
+				d.ActiveDays++
+			}
+		}
+
+		if day.ContributionCount > 0 {
+			stats.ActiveDays++
+		}
+	}
+	stats.LongestGap = longestSpan(days, func(count int) bool { return count == 0 })
+	stats.ActivePercentage = 100 * float64(stats.ActiveDays) / float64(len(days))
+
+	stats.Weekdays = weekdays
+	for _, distributions := range [][]Distribution{stats.Weekdays, stats.Months} {
+		for i, d := range distributions {
+			if d.Days > 0 {
+				distributions[i].Average = float64(d.Total) / float64(d.Days)
+			}
+		}
+	}
+
+	for _, n := range rollingWindows {
+		window := counts[max(len(counts)-n, 0):]
+		total := 0
+		for _, count := range window {
+			total += count
+		}
+		stats.RollingAverages = append(stats.RollingAverages, RollingAverage{Days: n, Average: float64(total) / float64(len(window))})
+	}
+
+	sorted := slices.Clone(counts)
+	slices.Sort(sorted)
+	if len(sorted)%2 == 0 {
+		stats.Median = float64(sorted[len(sorted)/2-1]+sorted[len(sorted)/2]) / 2
+	} else {
+		stats.Median = float64(sorted[len(sorted)/2])
+	}
+	for _, p := range []int{25, 75, 90, 99} {
+		stats.Percentiles = append(stats.Percentiles, Percentile{Percentile: p, Count: nearestRank(sorted, p)})
+	}
+	return stats
+}
+
+// longestSpan returns the first longest run of days whose counts match.
+func longestSpan(days []ContributionDay, match func(count int) bool) Span {
+	var longest, span Span
+	for _, day := range days {
+		if !match(day.ContributionCount) {
+			span = Span{}
+			continue
+		}
+		if span.Days == 0 {
+			span.From = day.Date
+		}
+		span.To = day.Date
+		span.Days++
+		if span.Days > longest.Days {
+			longest = span
+		}
+	}
+	return longest
+}
+
+// nearestRank returns the p-th percentile of sorted by the nearest-rank method.
+func nearestRank(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+func (s Stats) writeText(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sの%s ~ %sの統計\n", s.Login, s.From, s.To)
+	fmt.Fprintf(&b, "合計コミット数は%d\n", s.Total)
+	fmt.Fprintf(&b, "コミットした日は%d/%d日(%.1f%%)\n", s.ActiveDays, s.Days, s.ActivePercentage)
+	for _, r := range s.RollingAverages {
+		fmt.Fprintf(&b, "直近%d日の平均コミット数は%.2f\n", r.Days, r.Average)
+	}
+	fmt.Fprintf(&b, "中央値は%.1f", s.Median)
+	for _, p := range s.Percentiles {
+		fmt.Fprintf(&b, " p%dは%d", p.Percentile, p.Count)
+	}
+	b.WriteString("\n")
+	if s.BusiestDay.Date != "" {
+		fmt.Fprintf(&b, "最もコミットした日は%s(%d)\n", s.BusiestDay.Date, s.BusiestDay.ContributionCount)
+	}
+	if s.LongestGap.Days > 0 {
+		fmt.Fprintf(&b, "最長の空白は%d日(%s ~ %s)\n", s.LongestGap.Days, s.LongestGap.From, s.LongestGap.To)
+	}
+	b.WriteString("\n曜日別\n")
+	for _, d := range s.Weekdays {
+		fmt.Fprintf(&b, "%-9s %5d %6.2f\n", d.Name, d.Total, d.Average)
+	}
+	b.WriteString("\n月別\n")
+	for _, d := range s.Months {
+		fmt.Fprintf(&b, "%-9s %5d %6.2f\n", d.Name, d.Total, d.Average)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (a *App) stats(ctx context.Context, args []string, out io.Writer) error {
+	fs := newFlagSet("stats")
+	login := fs.String("login", os.Getenv("GH_USER_NAME"), "GitHub login to summarize")
+	format := fs.String("format", "text", "output format: text or json")
+	fromValue := fs.String("from", "", "first day to summarize as YYYY-MM-DD, a year before -to by default")
+	toValue := fs.String("to", "", "last day to summarize as YYYY-MM-DD, today by default")
+	input := fs.String("input", "", "path to a saved calendar to summarize instead of asking GitHub")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("-login is required")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	days, err := a.calendarDays(ctx, *login, *input, *fromValue, *toValue)
+	if err != nil {
+		return err
+	}
+	stats := computeStats(*login, days)
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	return stats.writeText(out)
+}
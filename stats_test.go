@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func TestComputeStats(t *testing.T) {
+	// 2023-01-01 is a Sunday
+	days := []ContributionDay{
+		{Date: "2022-12-30", ContributionCount: 2},
+		{Date: "2022-12-31", ContributionCount: 0},
+		{Date: "2023-01-01", ContributionCount: 0},
+		{Date: "2023-01-02", ContributionCount: 0},
+		{Date: "2023-01-03", ContributionCount: 5},
+		{Date: "2023-01-04", ContributionCount: 1},
+		{Date: "2023-01-05", ContributionCount: 0},
+		{Date: "2023-01-06", ContributionCount: 4},
+	}
+	got := computeStats("octocat", days)
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "range", got: []string{got.From, got.To}, want: []string{"2022-12-30", "2023-01-06"}},
+		{name: "total", got: got.Total, want: 12},
+		{name: "activeDays", got: got.ActiveDays, want: 4},
+		{name: "activePercentage", got: got.ActivePercentage, want: 50.0},
+		{name: "rollingAverages", got: got.RollingAverages, want: []RollingAverage{{Days: 7, Average: 10.0 / 7}, {Days: 30, Average: 1.5}, {Days: 90, Average: 1.5}}},
+		{name: "median", got: got.Median, want: 0.5},
+		{name: "percentiles", got: got.Percentiles, want: []Percentile{{Percentile: 25, Count: 0}, {Percentile: 75, Count: 2}, {Percentile: 90, Count: 5}, {Percentile: 99, Count: 5}}},
+		{name: "busiestDay", got: got.BusiestDay, want: ContributionDay{Date: "2023-01-03", ContributionCount: 5}},
+		{name: "longestGap", got: got.LongestGap, want: Span{From: "2022-12-31", To: "2023-01-02", Days: 3}},
+		{name: "friday", got: got.Weekdays[time.Friday], want: Distribution{Name: "Friday", Days: 2, ActiveDays: 2, Total: 6, Average: 3}},
+		{name: "sunday", got: got.Weekdays[time.Sunday], want: Distribution{Name: "Sunday", Days: 1}},
+		{name: "months", got: got.Months, want: []Distribution{{Name: "2022-12", Days: 2, ActiveDays: 1, Total: 2, Average: 1}, {Name: "2023-01", Days: 6, ActiveDays: 3, Total: 10, Average: 10.0 / 6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("computeStats() %s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeStatsEmpty(t *testing.T) {
+	got := computeStats("octocat", nil)
+	if got.Days != 0 || got.Total != 0 || got.BusiestDay.Date != "" || len(got.Weekdays) != 0 {
+		t.Errorf("computeStats() = %v, want empty stats", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(todayIsOneJson)
+	})
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		now:           func() time.Time { return time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC) },
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		want    string
+	}{
+		{name: "text", args: []string{"-login", "octocat"}, want: "octocatの"},
+		{name: "json", args: []string{"-login", "octocat", "-format", "json"}, want: `"login": "octocat"`},
+		{name: "range", args: []string{"-login", "octocat", "-from", "2023-01-02", "-to", "2023-01-03"}, want: "octocatの2023-01-02 ~ 2023-01-03の統計"},
+		{name: "invalidRange", args: []string{"-login", "octocat", "-from", "2023-01-04", "-to", "2023-01-03"}, wantErr: "from must not be after to"},
+		{name: "noLogin", args: []string{"-login", ""}, wantErr: "-login is required"},
+		{name: "unknownFormat", args: []string{"-login", "octocat", "-format", "xml"}, wantErr: `unknown format "xml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := app.stats(context.Background(), tt.args, &b)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("stats() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stats() err = %v", err)
+			}
+			if !strings.Contains(b.String(), tt.want) {
+				t.Errorf("stats() = %v, want to contain %v", b.String(), tt.want)
+			}
+			if tt.name == "json" && !json.Valid(b.Bytes()) {
+				t.Errorf("stats() = %v, want valid JSON", b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order once each; the number applied is kept in PRAGMA user_version.
+var migrations = []string{
+	`CREATE TABLE users (
+		login TEXT PRIMARY KEY,
+		created_at TEXT NOT NULL
+	);
+	CREATE TABLE contribution_days (
+		login TEXT NOT NULL REFERENCES users (login),
+		date TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (login, date)
+	);
+	CREATE TABLE runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		login TEXT NOT NULL REFERENCES users (login),
+		ran_at TEXT NOT NULL,
+		today TEXT NOT NULL,
+		today_count INTEGER NOT NULL,
+		latest_day TEXT NOT NULL,
+		total INTEGER NOT NULL,
+		streak INTEGER NOT NULL
+	);
+	CREATE INDEX runs_login_ran_at ON runs (login, ran_at);`,
+	`ALTER TABLE contribution_days ADD COLUMN contribution_level TEXT NOT NULL DEFAULT '';
+	ALTER TABLE contribution_days ADD COLUMN color TEXT NOT NULL DEFAULT '';`,
+}
+
+// Store keeps the calendars and results fetched from GitHub in SQLite, so
+// history can be queried locally and the last known data reported while
+// GitHub can not be reached.
+type Store struct {
+	db *sql.DB
+}
+
+// openStore opens the database at path, creating it and applying pending migrations.
+func openStore(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// one connection serializes the writes of concurrent jobs
+	db.SetMaxOpenConns(1)
+	store := &Store{db: db}
+	if err := store.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("can not migrate %s: %w", path, err)
+	}
+	return store, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		slog.Info("migrated store", "version", i+1)
+	}
+	return nil
+}
+
+func (s *Store) saveUser(ctx context.Context, tx *sql.Tx, login string) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO users (login, created_at) VALUES (?, ?) ON CONFLICT (login) DO NOTHING", login, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// saveDays stores days of login, replacing the counts of days already stored.
+func (s *Store) saveDays(ctx context.Context, login string, days []ContributionDay) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.saveUser(ctx, tx, login); err != nil {
+		return err
+	}
+	for _, day := range days {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO contribution_days (login, date, count, contribution_level, color) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (login, date) DO UPDATE SET count = excluded.count, contribution_level = excluded.contribution_level, color = excluded.color`,
+			login, day.Date, day.ContributionCount, day.ContributionLevel, day.Color); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// days returns the stored days of login from from to to in order.
+func (s *Store) days(ctx context.Context, login string, from time.Time, to time.Time) ([]ContributionDay, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT date, count, contribution_level, color FROM contribution_days WHERE login = ? AND date BETWEEN ? AND ? ORDER BY date", login, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	days := []ContributionDay{}
+	for rows.Next() {
+		var day ContributionDay
+		if err := rows.Scan(&day.Date, &day.ContributionCount, &day.ContributionLevel, &day.Color); err != nil {
+			return nil, err
+		}
+		if d, err := time.Parse("2006-01-02", day.Date); err == nil {
+			day.Weekday = int(d.Weekday())
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+func (s *Store) saveResult(ctx context.Context, result *Result, ranAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := s.saveUser(ctx, tx, result.userName); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO runs (login, ran_at, today, today_count, latest_day, total, streak) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		result.userName, ranAt.UTC().Format(time.RFC3339), result.today.Format("2006-01-02"), result.todayContributionCount, result.latestDay.Format("2006-01-02"), result.total, result.streak); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+var errNoResult = errors.New("no result is stored")
+
+// lastResult returns the latest result stored for login and when it was counted.
+func (s *Store) lastResult(ctx context.Context, login string) (Result, time.Time, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT login, ran_at, today, today_count, latest_day, total, streak FROM runs WHERE login = ? ORDER BY ran_at DESC, id DESC LIMIT 1", login)
+	result, ranAt, err := scanResult(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Result{}, time.Time{}, errNoResult
+	}
+	return result, ranAt, err
+}
+
+// leaderboard returns the latest result of every login, the longest streak first.
+func (s *Store) leaderboard(ctx context.Context) ([]Result, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT login, ran_at, today, today_count, latest_day, total, streak FROM runs
+		WHERE id IN (SELECT MAX(id) FROM runs GROUP BY login)
+		ORDER BY streak DESC, total DESC, login`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []Result{}
+	for rows.Next() {
+		result, _, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// scanResult reads the columns of a run selected by lastResult and leaderboard.
+func scanResult(row interface{ Scan(...any) error }) (Result, time.Time, error) {
+	var ranAt, today, latestDay string
+	var result Result
+	if err := row.Scan(&result.userName, &ranAt, &today, &result.todayContributionCount, &latestDay, &result.total, &result.streak); err != nil {
+		return Result{}, time.Time{}, err
+	}
+	at, err := time.Parse(time.RFC3339, ranAt)
+	if err != nil {
+		return Result{}, time.Time{}, err
+	}
+	if result.today, err = time.Parse("2006-01-02", today); err != nil {
+		return Result{}, time.Time{}, err
+	}
+	if result.latestDay, err = time.Parse("2006-01-02", latestDay); err != nil {
+		return Result{}, time.Time{}, err
+	}
+	return result, at, nil
+}
+
+// fetchCalendar fetches the days of [from, to] and stores them. While GitHub
+// can not be reached the stored days are returned instead, if there are any.
+func (a *App) fetchCalendar(ctx context.Context, login string, from time.Time, to time.Time) ([]ContributionDay, error) {
+	days, err := Client{a.graphqlClient}.fetchCalendar(ctx, login, from, to)
+	if a.store == nil {
+		return days, err
+	}
+	if err == nil {
+		if err := a.store.saveDays(ctx, login, days); err != nil {
+			slog.Error("can not store calendar", "user", login, "err", err)
+		}
+		return days, nil
+	}
+	stored, storeErr := a.store.days(ctx, login, from, to)
+	if storeErr != nil || len(stored) == 0 {
+		return nil, err
+	}
+	slog.Warn("using stored calendar", "user", login, "err", err)
+	return stored, nil
+}
+
+// reportLastKnown posts the last stored result of login when it can not be counted now, and reports whether it did.
+func (a *App) reportLastKnown(ctx context.Context, login string, now time.Time) bool {
+	if a.store == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), slackErrorTimeout)
+	defer cancel()
+	result, ranAt, err := a.store.lastResult(ctx, login)
+	if err != nil {
+		slog.Error("can not load last result", "user", login, "err", err)
+		return false
+	}
+	message := fmt.Sprintf("GitHubから取得できなかったため%s時点の記録です\n%s", ranAt.In(now.Location()).Format("2006-01-02 15:04"), result.createMessage())
+	a.slackClient.postSlackTo(ctx, a.channel(ctx, login), message)
+	return true
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := openStore(context.Background(), filepath.Join(t.TempDir(), "count-commits.db"))
+	if err != nil {
+		t.Fatalf("openStore() err = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestOpenStoreMigratesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "count-commits.db")
+	for range 2 {
+		store, err := openStore(context.Background(), path)
+		if err != nil {
+			t.Fatalf("openStore() err = %v", err)
+		}
+		var version int
+		store.db.QueryRow("PRAGMA user_version").Scan(&version)
+		store.Close()
+		if version != len(migrations) {
+			t.Errorf("user_version = %v, want %v", version, len(migrations))
+		}
+	}
+}
+
+func TestStoreDays(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	store.saveDays(ctx, "octocat", []ContributionDay{{Date: "2023-01-01", ContributionCount: 1}, {Date: "2023-01-02", ContributionCount: 0}})
+	// a later fetch corrects the count of a day
+	store.saveDays(ctx, "octocat", []ContributionDay{{Date: "2023-01-02", ContributionCount: 3, ContributionLevel: "FOURTH_QUARTILE", Color: "#216e39"}, {Date: "2023-01-03", ContributionCount: 2}})
+	store.saveDays(ctx, "hubot", []ContributionDay{{Date: "2023-01-02", ContributionCount: 9}})
+
+	got, err := store.days(ctx, "octocat", date("2023-01-02"), date("2023-01-03"))
+	want := []ContributionDay{{Date: "2023-01-02", ContributionCount: 3, ContributionLevel: "FOURTH_QUARTILE", Color: "#216e39", Weekday: 1}, {Date: "2023-01-03", ContributionCount: 2, Weekday: 2}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("days() = %v %v, want %v", got, err, want)
+	}
+}
+
+func TestStoreResults(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	if _, _, err := store.lastResult(ctx, "octocat"); err != errNoResult {
+		t.Errorf("lastResult() err = %v, want %v", err, errNoResult)
+	}
+
+	ranAt := time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC)
+	results := []Result{
+		{userName: "octocat", today: date("2023-01-02"), todayContributionCount: 1, latestDay: date("2022-12-31"), total: 5, streak: 3},
+		{userName: "octocat", today: date("2023-01-03"), todayContributionCount: 2, latestDay: date("2022-12-31"), total: 7, streak: 4},
+		{userName: "hubot", today: date("2023-01-03"), todayContributionCount: 1, latestDay: date("2022-12-01"), total: 40, streak: 34},
+	}
+	for i, result := range results {
+		if err := store.saveResult(ctx, &result, ranAt.AddDate(0, 0, i)); err != nil {
+			t.Fatalf("saveResult() err = %v", err)
+		}
+	}
+
+	got, gotAt, err := store.lastResult(ctx, "octocat")
+	if err != nil || got != results[1] || !gotAt.Equal(ranAt.AddDate(0, 0, 1)) {
+		t.Errorf("lastResult() = %+v %v %v, want %+v %v", got, gotAt, err, results[1], ranAt.AddDate(0, 0, 1))
+	}
+	leaderboard, err := store.leaderboard(ctx)
+	if err != nil || !reflect.DeepEqual(leaderboard, []Result{results[2], results[1]}) {
+		t.Errorf("leaderboard() = %+v %v, want %+v", leaderboard, err, []Result{results[2], results[1]})
+	}
+}
+
+func TestFetchCalendarFromStore(t *testing.T) {
+	totalContributionsIsOneJson, _ := testData.ReadFile("testdata/ExecQuery/totalContributionsIsOne.json")
+	up := true
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(totalContributionsIsOneJson)
+	})
+	app := &App{graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}), store: newTestStore(t)}
+	ctx := context.Background()
+	want := []ContributionDay{{Date: "2023-01-01", ContributionCount: 1}}
+
+	if _, err := app.fetchCalendar(ctx, "hubot", date("2022-12-01"), date("2023-01-03")); err != nil {
+		t.Fatalf("fetchCalendar() err = %v", err)
+	}
+	up = false
+	if got, err := app.fetchCalendar(ctx, "hubot", date("2022-12-01"), date("2023-01-03")); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("fetchCalendar() while down = %v %v, want %v", got, err, want)
+	}
+	if _, err := app.fetchCalendar(ctx, "octocat", date("2022-12-01"), date("2023-01-03")); err == nil {
+		t.Errorf("fetchCalendar() of a login never stored err = nil, want the GitHub error")
+	}
+}
+
+func TestRunReportsLastKnown(t *testing.T) {
+	okJson, _ := testData.ReadFile("testdata/slack/ok.json")
+	var got []string
+	ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+		c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			got = append(got, req.PostForm.Get("text"))
+			w.Write(okJson)
+		})
+	})
+	ts.Start()
+	defer ts.Stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	store := newTestStore(t)
+	last := Result{userName: "octocat", today: date("2023-01-02"), todayContributionCount: 2, latestDay: date("2022-12-31"), total: 5, streak: 3}
+	store.saveResult(context.Background(), &last, time.Date(2023, 1, 2, 11, 37, 0, 0, time.UTC))
+	state, _ := loadState("")
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+		strategy:      "sequential",
+		state:         state,
+		store:         store,
+	}
+	app.run(context.Background(), "octocat", time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC))
+
+	want := "GitHubから取得できなかったため2023-01-02 11:37時点の記録です\n" + last.createMessage()
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("run() posted %v, want %v", got, want)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	var got []string
+	ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+		c.Handle("/chat.postMessage", func(w http.ResponseWriter, req *http.Request) {
+			req.ParseForm()
+			got = append(got, req.PostForm.Get("text"))
+		})
+	})
+	ts.Start()
+	defer ts.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		cancel()
+	})
+	store := newTestStore(t)
+	last := Result{userName: "octocat", today: date("2023-01-02"), latestDay: date("2023-01-02")}
+	store.saveResult(context.Background(), &last, time.Date(2023, 1, 2, 11, 37, 0, 0, time.UTC))
+	state, _ := loadState("")
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+		strategy:      "sequential",
+		state:         state,
+		store:         store,
+	}
+	app.run(ctx, "octocat", time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC))
+	if len(got) != 0 {
+		t.Errorf("run() posted %v after being canceled, want nothing", got)
+	}
+}
+
+func TestRunStoresDays(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(todayIsOneJson)
+	})
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		strategy:      "sequential",
+		store:         newTestStore(t),
+	}
+	now := time.Date(2023, 1, 3, 11, 37, 0, 0, time.UTC)
+	if _, err := app.streak(context.Background(), "octocat", now); err != nil {
+		t.Fatalf("streak() err = %v", err)
+	}
+	if _, ranAt, err := app.store.lastResult(context.Background(), "octocat"); err != nil || !ranAt.Equal(now) {
+		t.Errorf("lastResult() after streak() ran at %v %v, want %v", ranAt, err, now)
+	}
+
+	got, err := app.store.days(context.Background(), "octocat", date("2023-01-02"), date("2023-01-03"))
+	want := []ContributionDay{{Date: "2023-01-02", ContributionCount: 0, Weekday: 1}, {Date: "2023-01-03", ContributionCount: 1, Weekday: 2}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("days() after streak() = %v %v, want %v", got, err, want)
+	}
+}
+
+func TestAPILeaderboard(t *testing.T) {
+	api := newTestAPI(t, func(w http.ResponseWriter, _ *http.Request) {})
+	rec := httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, httptest.NewRequest("GET", "/leaderboard", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /leaderboard without store status = %v, want %v", rec.Code, http.StatusNotFound)
+	}
+
+	api.app.store = newTestStore(t)
+	result := Result{userName: "octocat", today: date("2023-01-03"), todayContributionCount: 1, latestDay: date("2023-01-01"), total: 3, streak: 3}
+	api.app.store.saveResult(context.Background(), &result, time.Now())
+	rec = httptest.NewRecorder()
+	api.handler().ServeHTTP(rec, httptest.NewRequest("GET", "/leaderboard", nil))
+	if want := `[{"login":"octocat","today":"2023-01-03","todayContributionCount":1,"streak":3,"total":3,"average":1,"from":"2023-01-01"}]`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Errorf("GET /leaderboard = %v, want %v", rec.Body.String(), want)
+	}
+}
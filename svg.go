@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	svgMonthHeight = 15
+	// badges use 11px Verdana, about this wide per character
+	badgeCharWidth = 7
+	badgePadding   = 10
+)
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// writeCalendarSVG draws the last year like GitHub's profile, with month names above the weeks they start in.
+func writeCalendarSVG(w io.Writer, weeks []Week) error {
+	columns := calendarColumns(weeks, heatmapWeeks)
+	scale := newLevelScale(weeks)
+	step := heatmapCell + heatmapGap
+	width := len(columns)*step - heatmapGap
+	height := svgMonthHeight + 7*step - heatmapGap
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="-apple-system,BlinkMacSystemFont,Segoe UI,Helvetica,Arial,sans-serif" font-size="9">`+"\n", width, height, width, height)
+	for x, column := range columns {
+		for _, day := range column {
+			if day == nil || day.Date[8:] != "01" {
+				continue
+			}
+			d, _ := time.Parse("2006-01-02", day.Date)
+			fmt.Fprintf(&buf, `<text x="%d" y="%d" fill="#57606a">%s</text>`+"\n", x*step, svgMonthHeight-5, d.Month().String()[:3])
+		}
+		for y, day := range column {
+			if day == nil {
+				continue
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s on %s</title></rect>`+"\n", x*step, svgMonthHeight+y*step, heatmapCell, heatmapCell, hexColor(scale.dayColor(*day)), plural(day.ContributionCount, "contribution"), escapeXML(day.Date))
+		}
+	}
+	buf.WriteString("</svg>\n")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func badgeColor(streak int) string {
+	switch {
+	case streak == 0:
+		return "#9f9f9f"
+	case streak < 7:
+		return "#dfb317"
+	case streak < 30:
+		return "#97ca00"
+	default:
+		return "#4c1"
+	}
+}
+
+// writeBadgeSVG draws a flat shields.io style badge of label and value.
+func writeBadgeSVG(w io.Writer, label string, value string, color string) error {
+	labelWidth := utf8.RuneCountInString(label)*badgeCharWidth + badgePadding
+	valueWidth := utf8.RuneCountInString(value)*badgeCharWidth + badgePadding
+	width := labelWidth + valueWidth
+	label, value = escapeXML(label), escapeXML(value)
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+<title>%s: %s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>
+</g>
+</svg>
+`, width, label, value, label, value, width, labelWidth, labelWidth, valueWidth, color, width,
+		labelWidth/2, label, labelWidth/2, label,
+		labelWidth+valueWidth/2, value, labelWidth+valueWidth/2, value)
+	return err
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+	return writeFileAtomic(path, write)
+}
+
+// svg writes the calendar and the streak badge of a login for READMEs and wikis.
+func (a *App) svg(ctx context.Context, args []string) error {
+	fs := newFlagSet("svg")
+	login := fs.String("login", os.Getenv("GH_USER_NAME"), "GitHub login to draw")
+	calendarPath := fs.String("calendar", "", "path to write the contribution calendar SVG to, - for stdout")
+	badgePath := fs.String("badge", "", "path to write the streak badge SVG to, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("-login is required")
+	}
+	if *calendarPath == "" && *badgePath == "" {
+		return errors.New("-calendar or -badge is required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	now := a.now()
+	if *calendarPath != "" {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		weeks, err := Client{a.graphqlClient}.fetchWeeks(ctx, *login, today.AddDate(0, 0, -365), today)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(*calendarPath, func(w io.Writer) error { return writeCalendarSVG(w, weeks) }); err != nil {
+			return err
+		}
+	}
+	if *badgePath != "" {
+		result, err := a.streak(ctx, *login, now)
+		if err != nil {
+			return err
+		}
+		value := plural(result.streak, "day")
+		if err := writeFile(*badgePath, func(w io.Writer) error { return writeBadgeSVG(w, "streak", value, badgeColor(result.streak)) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
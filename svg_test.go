@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func isXML(b []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func TestWriteCalendarSVG(t *testing.T) {
+	weeks := []Week{
+		{ContributionDays: []ContributionDay{{ContributionCount: 0, Date: "2022-12-30"}, {ContributionCount: 2, Date: "2022-12-31", Color: "#39d353"}}},
+		{ContributionDays: []ContributionDay{{ContributionCount: 4, Date: "2023-01-01"}, {ContributionCount: 1, Date: "2023-01-02"}}},
+	}
+	var buf bytes.Buffer
+	if err := writeCalendarSVG(&buf, weeks); err != nil {
+		t.Fatalf("writeCalendarSVG() err = %v", err)
+	}
+	got := buf.String()
+	if !isXML(buf.Bytes()) {
+		t.Errorf("writeCalendarSVG() = %v, want XML", got)
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "header", want: `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="104" viewBox="0 0 24 104"`},
+		{name: "month", want: `<text x="13" y="10" fill="#57606a">Jan</text>`},
+		{name: "zero", want: `<rect x="0" y="80" width="11" height="11" rx="2" fill="#ebedf0"><title>0 contributions on 2022-12-30</title></rect>`},
+		{name: "busiest", want: `<rect x="13" y="15" width="11" height="11" rx="2" fill="#216e39"><title>4 contributions on 2023-01-01</title></rect>`},
+		{name: "githubColor", want: `<rect x="0" y="93" width="11" height="11" rx="2" fill="#39d353"><title>2 contributions on 2022-12-31</title></rect>`},
+		{name: "quiet", want: `<rect x="13" y="28" width="11" height="11" rx="2" fill="#9be9a8"><title>1 contribution on 2023-01-02</title></rect>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("writeCalendarSVG() = %v, want to contain %v", got, tt.want)
+			}
+		})
+	}
+	if n := strings.Count(got, "<rect "); n != 4 {
+		t.Errorf("writeCalendarSVG() has %v days, want 4", n)
+	}
+}
+
+func TestWriteBadgeSVG(t *testing.T) {
+	tests := []struct {
+		name  string
+		label string
+		value string
+		want  []string
+	}{
+		{
+			name:  "streak",
+			label: "streak",
+			value: "3 days",
+			want:  []string{`width="104"`, `aria-label="streak: 3 days"`, `<rect x="52" width="52" height="20" fill="#dfb317"/>`, `<text x="78" y="14">3 days</text>`},
+		},
+		{
+			name:  "escaped",
+			label: "a&b",
+			value: "<1>",
+			want:  []string{`aria-label="a&amp;b: &lt;1&gt;"`, `<text x="15" y="14">a&amp;b</text>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeBadgeSVG(&buf, tt.label, tt.value, badgeColor(3)); err != nil {
+				t.Fatalf("writeBadgeSVG() err = %v", err)
+			}
+			if !isXML(buf.Bytes()) {
+				t.Errorf("writeBadgeSVG() = %v, want XML", buf.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("writeBadgeSVG() = %v, want to contain %v", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBadgeColor(t *testing.T) {
+	tests := []struct {
+		streak int
+		want   string
+	}{
+		{streak: 0, want: "#9f9f9f"},
+		{streak: 1, want: "#dfb317"},
+		{streak: 7, want: "#97ca00"},
+		{streak: 30, want: "#4c1"},
+	}
+	for _, tt := range tests {
+		if got := badgeColor(tt.streak); got != tt.want {
+			t.Errorf("badgeColor(%v) = %v, want %v", tt.streak, got, tt.want)
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(todayIsOneJson)
+	})
+	app := &App{
+		graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+		strategy:      "sequential",
+		now:           func() time.Time { return time.Date(2023, 1, 3, 12, 0, 0, 0, time.UTC) },
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		want    map[string]string
+	}{
+		{
+			name: "calendarAndBadge",
+			args: []string{"-login", "octocat", "-calendar", filepath.Join(dir, "calendar.svg"), "-badge", filepath.Join(dir, "badge.svg")},
+			want: map[string]string{"calendar.svg": "1 contribution on 2023-01-03", "badge.svg": `aria-label="streak: 1 day"`},
+		},
+		{
+			name:    "noLogin",
+			args:    []string{"-login", "", "-badge", filepath.Join(dir, "badge.svg")},
+			wantErr: "-login is required",
+		},
+		{
+			name:    "noOutput",
+			args:    []string{"-login", "octocat"},
+			wantErr: "-calendar or -badge is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := app.svg(context.Background(), tt.args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("svg() err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("svg() err = %v", err)
+			}
+			for name, want := range tt.want {
+				got, _ := os.ReadFile(filepath.Join(dir, name))
+				if !strings.Contains(string(got), want) {
+					t.Errorf("svg() %s = %v, want to contain %v", name, string(got), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	colorModeTrue = "truecolor"
+	colorMode256  = "256"
+	colorModeNone = "none"
+)
+
+// monochromeCells stand in for the colors of levels 0 to 4 without color support.
+var monochromeCells = [5]string{"· ", "░░", "▒▒", "▓▓", "██"}
+
+// detectColorMode follows the usual conventions: NO_COLOR and dumb terminals
+// get no colors, COLORTERM announces truecolor and TERM 256 colors.
+func detectColorMode(out *os.File) string {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return colorModeNone
+	}
+	if info, err := out.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return colorModeNone
+	}
+	if colorTerm := os.Getenv("COLORTERM"); colorTerm == "truecolor" || colorTerm == "24bit" {
+		return colorModeTrue
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return colorMode256
+	}
+	return colorModeNone
+}
+
+// xterm256 returns the closest color of the 256 color palette, either of its
+// 6x6x6 cube or of its gray ramp.
+func xterm256(c color.RGBA) int {
+	steps := [6]int{0, 95, 135, 175, 215, 255}
+	nearest := func(v uint8) int {
+		best := 0
+		for i, step := range steps {
+			if abs(int(v)-step) < abs(int(v)-steps[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	distance := func(r, g, b int) int {
+		return (int(c.R)-r)*(int(c.R)-r) + (int(c.G)-g)*(int(c.G)-g) + (int(c.B)-b)*(int(c.B)-b)
+	}
+
+	r, g, b := nearest(c.R), nearest(c.G), nearest(c.B)
+	best, bestDistance := 16+36*r+6*g+b, distance(steps[r], steps[g], steps[b])
+	for i := range 24 {
+		gray := 8 + 10*i
+		if d := distance(gray, gray, gray); d < bestDistance {
+			best, bestDistance = 232+i, d
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// terminalCell draws a day in c, or by its level without colors.
+func terminalCell(c color.RGBA, level int, mode string) string {
+	switch mode {
+	case colorModeTrue:
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm■\x1b[0m ", c.R, c.G, c.B)
+	case colorMode256:
+		return fmt.Sprintf("\x1b[38;5;%dm■\x1b[0m ", xterm256(c))
+	default:
+		return monochromeCells[level]
+	}
+}
+
+// writeTerminalCalendar prints the last year as GitHub draws it, a row per
+// weekday and a column per week, with month names over the weeks they start in.
+func writeTerminalCalendar(w io.Writer, weeks []Week, mode string) error {
+	columns := calendarColumns(weeks, heatmapWeeks)
+	scale := newLevelScale(weeks)
+	out := bufio.NewWriter(w)
+
+	// the name of a month starting in the last week runs past the grid
+	months := []byte(strings.Repeat(" ", len(columns)*2+7))
+	for x, column := range columns {
+		for _, day := range column {
+			if day == nil || day.Date[8:] != "01" {
+				continue
+			}
+			d, _ := time.Parse("2006-01-02", day.Date)
+			copy(months[4+x*2:], d.Month().String()[:3])
+		}
+	}
+	fmt.Fprintln(out, strings.TrimRight(string(months), " "))
+
+	labels := [7]string{"   ", "Mon", "   ", "Wed", "   ", "Fri", "   "}
+	for y := range 7 {
+		line := labels[y] + " "
+		for _, column := range columns {
+			if column[y] == nil {
+				line += "  "
+				continue
+			}
+			line += terminalCell(scale.dayColor(*column[y]), scale.dayLevel(*column[y]), mode)
+		}
+		fmt.Fprintln(out, strings.TrimRight(line, " "))
+	}
+
+	legend := "Less "
+	for level := range monochromeCells {
+		legend += terminalCell(heatmapColors[level], level, mode)
+	}
+	fmt.Fprintln(out, legend+"More")
+	return out.Flush()
+}
+
+// streakCommand prints the streak of a login and, with -calendar, the last year as a heatmap.
+func (a *App) streakCommand(ctx context.Context, args []string, out *os.File) error {
+	fs := newFlagSet("streak")
+	login := fs.String("login", os.Getenv("GH_USER_NAME"), "GitHub login to count")
+	calendar := fs.Bool("calendar", false, "print the contribution calendar of the last year")
+	mode := fs.String("color", "auto", "colors of the calendar: auto, truecolor, 256 or none")
+	input := fs.String("input", "", "path to a saved calendar to count instead of asking GitHub")
+	todayValue := fs.String("today", "", "day to count up to as YYYY-MM-DD with -input, its last day by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("-login is required")
+	}
+	if *mode == "auto" {
+		*mode = detectColorMode(out)
+	}
+	if *input != "" {
+		return offlineStreakCommand(*login, *input, *todayValue, *calendar, *mode, out)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout())
+	defer cancel()
+
+	result, err := a.streak(ctx, *login, a.now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, result.createCommandMessage())
+	if !*calendar {
+		return nil
+	}
+	weeks, err := Client{a.graphqlClient}.fetchWeeks(ctx, *login, result.today.AddDate(0, 0, -365), result.today)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return writeTerminalCalendar(out, weeks, *mode)
+}
+
+func offlineStreakCommand(login string, input string, todayValue string, calendar bool, mode string, out io.Writer) error {
+	query, err := loadCalendarFile(input)
+	if err != nil {
+		return err
+	}
+	days := query.days()
+	today, err := lastDate(days)
+	if todayValue != "" {
+		today, err = time.Parse("2006-01-02", todayValue)
+	}
+	if err != nil {
+		return err
+	}
+	result, err := offlineStreak(login, query, today)
+	if errors.Is(err, errCalendarTooShort) {
+		slog.Warn("the streak is longer than counted", "user", login, "err", err)
+	} else if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, result.createCommandMessage())
+	if !calendar {
+		return nil
+	}
+	fmt.Fprintln(out)
+	return writeTerminalCalendar(out, weeksOf(filterDays(days, today.AddDate(0, 0, -365).Format("2006-01-02"), today.Format("2006-01-02"))), mode)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestXterm256(t *testing.T) {
+	tests := []struct {
+		name  string
+		color color.RGBA
+		want  int
+	}{
+		{name: "black", color: color.RGBA{0, 0, 0, 255}, want: 16},
+		{name: "white", color: color.RGBA{255, 255, 255, 255}, want: 231},
+		{name: "empty", color: heatmapColors[0], want: 255},
+		{name: "busiest", color: heatmapColors[4], want: 23},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := xterm256(tt.color); got != tt.want {
+				t.Errorf("xterm256() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTerminalCalendar(t *testing.T) {
+	weeks := []Week{
+		{ContributionDays: []ContributionDay{{Date: "2022-12-27", ContributionCount: 4}, {Date: "2022-12-28"}, {Date: "2022-12-29"}, {Date: "2022-12-30"}, {Date: "2022-12-31"}}},
+		{ContributionDays: []ContributionDay{{Date: "2023-01-01", ContributionCount: 1}, {Date: "2023-01-02", ContributionCount: 2}, {Date: "2023-01-03", ContributionCount: 3}}},
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		{
+			name: "none",
+			mode: colorModeNone,
+			want: "      Jan\n" +
+				"      ░░\n" +
+				"Mon   ▒▒\n" +
+				"    ██▓▓\n" +
+				"Wed ·\n" +
+				"    ·\n" +
+				"Fri ·\n" +
+				"    ·\n" +
+				"Less · ░░▒▒▓▓██More\n",
+		},
+		{
+			name: "truecolor",
+			mode: colorModeTrue,
+			want: "\x1b[38;2;33;110;57m■\x1b[0m",
+		},
+		{
+			name: "256",
+			mode: colorMode256,
+			want: "\x1b[38;5;255m■\x1b[0m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := writeTerminalCalendar(&b, weeks, tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			if tt.mode == colorModeNone && b.String() != tt.want {
+				t.Errorf("writeTerminalCalendar() = %q, want %q", b.String(), tt.want)
+			}
+			if tt.mode != colorModeNone && !strings.Contains(b.String(), tt.want) {
+				t.Errorf("writeTerminalCalendar() = %q, want to contain %q", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	messageModeThread  = "thread"
+	messageModeUpdate  = "update"
+	messageScopeDay    = "day"
+	messageScopeStreak = "streak"
+)
+
+// messageKey names the message runs share: the day by default, or the first day of the streak.
+func (r *Result) messageKey(scope string) string {
+	if scope == messageScopeStreak {
+		return "streak/" + r.latestDay.Format("2006-01-02")
+	}
+	return "day/" + r.today.Format("2006-01-02")
+}
+
+// postReport posts message as a new message unless messageMode is thread or
+// update and a message about the same key was posted before; then it replies
+// in that message's thread or edits it in place. It returns the message only
+// when a new one was posted.
+func (a *App) postReport(ctx context.Context, result *Result, message string, options []slack.MsgOption) PostedMessage {
+	key := result.messageKey(a.messageScope)
+	previous, ok := a.state.message(result.userName, key)
+	switch {
+	case ok && a.messageMode == messageModeThread:
+		a.slackClient.postSlackTo(ctx, previous.Channel, message, append(options, slack.MsgOptionTS(previous.TS))...)
+		return PostedMessage{}
+	case ok && a.messageMode == messageModeUpdate:
+		a.slackClient.updateSlack(ctx, previous, message, options...)
+		return PostedMessage{}
+	}
+	channel, ts := a.slackClient.postSlackTo(ctx, a.channel(ctx, result.userName), message, options...)
+	posted := PostedMessage{Key: key, Channel: channel, TS: ts}
+	if ts == "" || a.messageMode == "" {
+		return posted
+	}
+	if err := a.state.saveMessage(result.userName, posted); err != nil {
+		slog.Error("can not save state", "user", result.userName, "err", err)
+	}
+	return posted
+}
+
+func (client SlackClient) updateSlack(ctx context.Context, previous PostedMessage, message string, options ...slack.MsgOption) {
+	// Slack keeps the old blocks unless they are replaced, so clear them first in case options has none
+	options = append([]slack.MsgOption{slack.MsgOptionText(message, false), slack.MsgOptionBlocks([]slack.Block{}...)}, options...)
+	_, ts, _, err := client.UpdateMessageContext(ctx, previous.Channel, previous.TS, options...)
+	metrics.observeSlack(err)
+	if err != nil {
+		slog.Error("can not update message", "err", err)
+		return
+	}
+	slog.Info("updated message", "channel", previous.Channel, "ts", ts)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slacktest"
+)
+
+func TestPostReport(t *testing.T) {
+	todayAndYesterdayAreZeroJson, _ := testData.ReadFile("testdata/CountOverAYear/todayAndYesterdayAreZero.json")
+	todayIsOneJson, _ := testData.ReadFile("testdata/CountOverAYear/todayIsOne.json")
+	now := time.Date(2023, 1, 3, 18, 0, 0, 0, time.UTC)
+
+	type call struct {
+		method   string
+		threadTS string
+		ts       string
+		blocks   string
+	}
+	tests := []struct {
+		name  string
+		mode  string
+		scope string
+		runs  []time.Time
+		want  []call
+	}{
+		{
+			name: "newMessageEveryRun",
+			mode: "",
+			runs: []time.Time{now, now.Add(3 * time.Hour)},
+			want: []call{{method: "chat.postMessage"}, {method: "chat.postMessage"}},
+		},
+		{
+			name: "thread",
+			mode: messageModeThread,
+			runs: []time.Time{now, now.Add(3 * time.Hour), now.AddDate(0, 0, 1)},
+			want: []call{{method: "chat.postMessage"}, {method: "chat.postMessage", threadTS: "1503435956.000247"}, {method: "chat.postMessage"}},
+		},
+		{
+			name: "update",
+			mode: messageModeUpdate,
+			runs: []time.Time{now, now.Add(3 * time.Hour)},
+			want: []call{{method: "chat.postMessage"}, {method: "chat.update", ts: "1503435956.000247", blocks: "[]"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []call
+			record := func(method string) http.HandlerFunc {
+				return func(w http.ResponseWriter, req *http.Request) {
+					req.ParseForm()
+					got = append(got, call{method: method, threadTS: req.PostForm.Get("thread_ts"), ts: req.PostForm.Get("ts"), blocks: lastBlocks(req.PostForm)})
+					w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1503435956.000247"}`))
+				}
+			}
+			ts := slacktest.NewTestServer(func(c slacktest.Customize) {
+				c.Handle("/chat.postMessage", record("chat.postMessage"))
+				c.Handle("/chat.update", record("chat.update"))
+			})
+			ts.Start()
+			defer ts.Stop()
+
+			// the first day has no commits yet, the second has its first commit
+			mux := http.NewServeMux()
+			mux.HandleFunc("/graphql", func(w http.ResponseWriter, _ *http.Request) {
+				if len(got) == 0 {
+					w.Write(todayAndYesterdayAreZeroJson)
+				} else {
+					w.Write(todayIsOneJson)
+				}
+			})
+			path := filepath.Join(t.TempDir(), "state.json")
+			for _, run := range tt.runs {
+				// every run loads the state again like a new job would
+				state, err := loadState(path)
+				if err != nil {
+					t.Fatalf("loadState() err = %v", err)
+				}
+				app := &App{
+					graphqlClient: githubv4.NewClient(&http.Client{Transport: localRoundTripper{handler: mux}}),
+					slackClient:   SlackClient{slack.New("testToken", slack.OptionAPIURL(ts.GetAPIURL()))},
+					strategy:      "sequential",
+					state:         state,
+					messageMode:   tt.mode,
+					messageScope:  tt.scope,
+				}
+				app.run(context.Background(), "octocat", run)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("run() calls = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].method != tt.want[i].method || got[i].threadTS != tt.want[i].threadTS || got[i].ts != tt.want[i].ts || (tt.want[i].blocks != "" && got[i].blocks != tt.want[i].blocks) {
+					t.Errorf("run() calls[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func lastBlocks(form url.Values) string {
+	blocks := form["blocks"]
+	if len(blocks) == 0 {
+		return ""
+	}
+	return blocks[len(blocks)-1]
+}
+
+func TestMessageKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		scope  string
+		result Result
+		want   string
+	}{
+		{
+			name:   "day",
+			scope:  "",
+			result: Result{today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC)},
+			want:   "day/2023-01-03",
+		},
+		{
+			name:   "streak",
+			scope:  messageScopeStreak,
+			result: Result{today: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC)},
+			want:   "streak/2022-01-05",
+		},
+		{
+			name:   "sameStreakNextDay",
+			scope:  messageScopeStreak,
+			result: Result{today: time.Date(2023, 1, 4, 0, 0, 0, 0, time.UTC), latestDay: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC)},
+			want:   "streak/2022-01-05",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.messageKey(tt.scope); got != tt.want {
+				t.Errorf("messageKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}